@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"time"
+
+	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/lba-studio/n-cli/pkg/capture"
+	"github.com/lba-studio/n-cli/pkg/cronjob"
+	"github.com/lba-studio/n-cli/pkg/lockfile"
+	"github.com/lba-studio/n-cli/pkg/notifier"
+	"github.com/lba-studio/n-cli/pkg/notifier/marker"
+	"github.com/lba-studio/n-cli/pkg/runner"
+	"github.com/lba-studio/n-cli/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+// maxCronOutput bounds how much output n-cli cron holds on to, since it is
+// only printed once the command is done.
+const maxCronOutput = 4 << 20
+
+func NewCronCmd() *cobra.Command {
+	var useShell bool
+	var lockPath string
+	var name string
+	var tailLines int
+	var timeout, killAfter time.Duration
+	var killSignal string
+	var successExitCodes []int
+	c := &cobra.Command{
+		Use:   "cron",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "Run a scheduled command quietly and get notified when it fails.",
+		Long: `Runs a command from crontab or a systemd timer. Its output is held back: when it succeeds, n-cli prints nothing and sends no notification. When it fails, n-cli prints the output and sends one notification with the exit code, the duration and the last lines of output.
+
+Example: n-cli cron -- backup.sh --full
+
+  # crontab
+  0 3 * * * n-cli cron --lock /tmp/backup.lock -- /usr/local/bin/backup.sh
+
+Use --lock to skip a run while the previous one is still going. n-cli notifies you about the skipped run and exits with 0.
+
+n-cli remembers failures in ~/.n-cli/state, and notifies you when a job succeeds again after failing. Jobs are told apart by their command line and working directory, or by --name.
+
+n-cli exits with the exit code of the command.
+`,
+		Run: func(cobraCmd *cobra.Command, args []string) {
+			cfg, err := config.GetConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: Cannot read config: %s\n", err.Error())
+			}
+			runCfg := config.RunConfig{}
+			if cfg.Run != nil {
+				runCfg = *cfg.Run
+			}
+
+			args = runner.TrimSeparator(args)
+			commandLine := runner.CommandLine(args)
+			markerOpts := marker.Options{
+				NotifyOn:         marker.NotifyOnFailure,
+				SuccessExitCodes: successExitCodes,
+			}
+			if useShell {
+				shell := runner.ResolveShell(runCfg.Shell)
+				shellCommandLine, err := runner.ShellCommandLine(shell, args)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR: --shell: %s\n", err.Error())
+					os.Exit(1)
+				}
+				args = runner.ShellArgs(shell, shellCommandLine)
+				markerOpts.DisplayCommand = shellCommandLine
+			}
+			sig, err := runner.ParseSignal(killSignal)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: --kill-signal: %s\n", err.Error())
+				os.Exit(1)
+			}
+			cwd, _ := os.Getwd()
+			stateName := cronjob.StateName(name, commandLine, cwd)
+			store, err := cronStateStore()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: Cannot find the cron state: %s\n", err.Error())
+			}
+			var jobState cronjob.State
+			if store != nil {
+				if err := store.Load(stateName, &jobState); errors.Is(err, state.ErrInvalidName) {
+					fmt.Fprintln(os.Stderr, "ERROR: --name: expected letters, digits, '-' and '_'")
+					os.Exit(1)
+				} else if err != nil {
+					fmt.Fprintf(os.Stderr, "WARN: Cannot read the cron state: %s\n", err.Error())
+				}
+			}
+
+			var lock *lockfile.Lock
+			if lockPath != "" {
+				lock, err = lockfile.Acquire(lockPath)
+				if errors.Is(err, lockfile.ErrLocked) {
+					var holder *lockfile.Holder
+					if h, err := lockfile.ReadHolder(lockPath); err == nil {
+						holder = &h
+					}
+					cronNotify(cronjob.FormatSkipped(commandLine, lockPath, holder, time.Now()))
+					os.Exit(0)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR: --lock: %s\n", err.Error())
+					os.Exit(1)
+				}
+			}
+
+			// stdout and stderr share a writer, so that they stay in order
+			output := capture.NewBuffer(maxCronOutput)
+			var taps []io.Writer
+			if tailLines > 0 {
+				tail := capture.NewLineBuffer(tailLines)
+				markerOpts.OutputTail = tail
+				markerOpts.OutputTailSource = "output"
+				taps = append(taps, tail.Writer())
+			}
+			out := withTaps(output, taps)
+			cmd := exec.Command(args[0], args[1:]...)
+			cmd.Stdout = out
+			cmd.Stderr = out
+
+			m := marker.NewNotificationMarker(cmd, markerOpts)
+			startedAt := time.Now()
+			result := runAttempt(context.Background(), cmd, timeout, runner.Options{
+				KillSignal: sig,
+				KillAfter:  killAfter,
+				OnStart:    m.Start,
+			})
+			elapsed := time.Since(startedAt)
+
+			record := store != nil
+			stopped := true
+			if sig, ok := runner.Interrupted(cmd, result); ok {
+				m.Stopped("INTERRUPTED by " + runner.SignalName(sig))
+				record = false
+			} else if result.TimedOut {
+				m.Stopped(fmt.Sprintf("TIMED OUT after %s", timeout))
+			} else if cmd.ProcessState == nil && result.Err != nil {
+				m.Stopped("FAILED to start: " + result.Err.Error())
+			} else {
+				stopped = false
+			}
+			exitCode := runner.ExitCode(cmd, result)
+			succeeded := !stopped && cmd.ProcessState != nil && slices.Contains(successExitCodes, exitCode)
+			if !succeeded {
+				output.WriteTo(os.Stdout)
+				if cmd.ProcessState == nil && result.Err != nil {
+					fmt.Fprintf(os.Stderr, "n-cli cron error: %s\n", result.Err.Error())
+				}
+			}
+			m.Done()
+
+			if record {
+				failingSince := jobState.FailingSince
+				if failures := jobState.Record(succeeded, startedAt); failures > 0 {
+					cronNotify(cronjob.FormatRecovered(commandLine, failures, failingSince, elapsed))
+				}
+				if err := store.Save(stateName, jobState); err != nil {
+					fmt.Fprintf(os.Stderr, "WARN: Cannot save the cron state: %s\n", err.Error())
+				}
+			}
+			if lock != nil {
+				lock.Release()
+			}
+			os.Exit(exitCode)
+		},
+	}
+	// everything after the command belongs to the command, not to n-cli
+	c.Flags().SetInterspersed(false)
+	c.Flags().BoolVar(&useShell, "shell", false, "Run the command line through your shell (run.shell in config, $SHELL, or /bin/sh)")
+	c.Flags().StringVar(&lockPath, "lock", "", "Hold this lock file while the command runs, and skip the run if another one holds it")
+	c.Flags().StringVar(&name, "name", "", "Name of the job, used to remember its failures (default: derived from the command line and directory)")
+	c.Flags().IntVar(&tailLines, "tail", 20, "Add the last N lines of output to the failure notification")
+	c.Flags().IntSliceVar(&successExitCodes, "success-exit-codes", []int{0}, "Exit codes that count as success")
+	c.Flags().DurationVar(&timeout, "timeout", 0, "Stop the command if it runs longer than this, e.g. 2h. n-cli then exits with 124, like coreutils timeout")
+	c.Flags().StringVar(&killSignal, "kill-signal", "TERM", "Signal sent to the command's process group on --timeout")
+	c.Flags().DurationVar(&killAfter, "kill-after", 10*time.Second, "Send SIGKILL if the command is still running this long after --kill-signal (0 waits forever)")
+	return c
+}
+
+func cronStateStore() (*state.Store, error) {
+	dir, err := state.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return state.NewStore(dir), nil
+}
+
+// cronNotify sends a notification without printing anything on success, so
+// that cron has nothing to mail.
+func cronNotify(msg string) {
+	if err := notifier.NotifyTo(msg, io.Discard); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Cannot send the notification: %s\n", err.Error())
+	}
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/lba-studio/n-cli/cmd/logs"
+	"github.com/spf13/cobra"
+)
+
+func NewLogsCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "logs",
+		Short: "Read the output that n-cli run --log saved.",
+	}
+	c.AddCommand(logs.NewLogsLastCmd())
+	c.AddCommand(logs.NewLogsListCmd())
+	return c
+}
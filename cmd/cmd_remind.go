@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lba-studio/n-cli/cmd/remind"
+	"github.com/lba-studio/n-cli/pkg/reminder"
+	"github.com/spf13/cobra"
+)
+
+func NewRemindCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "remind <duration> <message>",
+		Short: "Send yourself a notification later, e.g. n-cli remind 25m \"stand-up\".",
+		Long: `Sends you a notification through your configured channels once the duration has passed. The reminder waits in a background process, so you can close your terminal.
+
+Example: n-cli remind 25m "stand-up"
+Example: n-cli remind 1h30m check the build
+Example: n-cli remind at 17:30 "go home"
+
+Use n-cli remind list to see pending reminders, and n-cli remind cancel <id> to cancel one. Pending reminders are kept in ~/.n-cli/reminders, but are not sent after a reboot: n-cli remind list reports them as missed once, then removes them.
+`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cobraCmd *cobra.Command, args []string) {
+			delay, err := reminder.ParseDelay(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+				os.Exit(1)
+			}
+			remind.Schedule(cobraCmd, time.Now().Add(delay), strings.Join(args[1:], " "))
+		},
+	}
+	c.AddCommand(remind.NewRemindAtCmd())
+	c.AddCommand(remind.NewRemindListCmd())
+	c.AddCommand(remind.NewRemindCancelCmd())
+	c.AddCommand(remind.NewRemindWaitCmd())
+	return c
+}
@@ -1,16 +1,53 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
 
+	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/lba-studio/n-cli/pkg/capture"
+	"github.com/lba-studio/n-cli/pkg/cgroup"
+	"github.com/lba-studio/n-cli/pkg/formatter"
+	"github.com/lba-studio/n-cli/pkg/history"
+	"github.com/lba-studio/n-cli/pkg/monitor"
 	"github.com/lba-studio/n-cli/pkg/notifier/marker"
+	"github.com/lba-studio/n-cli/pkg/runlog"
+	"github.com/lba-studio/n-cli/pkg/runner"
 	"github.com/spf13/cobra"
 )
 
 func NewRunCmd() *cobra.Command {
-	return &cobra.Command{
+	var useShell bool
+	var usePTY bool
+	var tailLines int
+	var tailStderrOnly bool
+	var notifyOn string
+	var timeout, killAfter time.Duration
+	var killSignal string
+	var retries int
+	var retryDelay time.Duration
+	var retryOnExit []int
+	var patterns []string
+	var patternThen string
+	var sampleInterval time.Duration
+	var metricsOut string
+	var useCgroup bool
+	var cgroupParent, memoryMax string
+	var cpuMax float64
+	var notifyIfSlower string
+	var noHistory bool
+	var etaNotifyAt int
+	var logPolicy string
+	markerOpts := marker.Options{}
+	c := &cobra.Command{
 		Use:     "run",
 		Aliases: []string{"r"},
 		Args:    cobra.MinimumNArgs(1),
@@ -19,30 +56,582 @@ func NewRunCmd() *cobra.Command {
 
 Example: n-cli run echo Hello, world!
 
-If you'd like to pass in flags to your shell command, use n-cli run mycommand -- --flag1=true --flag2.
+Flags after the command are passed to it, e.g. n-cli run ls -la. A -- right after the command is dropped, so n-cli run mycommand -- --flag1=true --flag2 still works, as does n-cli run -- mycommand --flag1=true --flag2.
+
+Use --tail N to add the last N lines of output (or only stderr, with --tail-stderr) to the notification when the command fails. Your terminal still gets the full output.
+
+Use --min-duration, --on and --success-exit-codes to decide when a notification is worth sending:
+
+  n-cli run --min-duration 1m --on failure make build
+  n-cli run --success-exit-codes 0,1 grep -r TODO .
+
+The exit code of n-cli is always the exit code of your command.
+
+Capturing output (--tail, --notify-on, --stall-after) means the command no longer writes to a terminal, so many tools drop colours and progress bars. Use --pty to run the command in a pseudo-terminal instead (Linux and macOS), which keeps it interactive too:
+
+  n-cli run --pty --tail 20 cargo build
+
+Use --log failure to save the complete output of failed runs to ~/.n-cli/logs (or --log always for every run). The notification then includes the log's path and a file:// link, or a link under run.logBaseUrl in your config. run.logMaxFiles (default 100) and run.logMaxSize (default 1G) limit how many logs are kept. Use n-cli logs to read them:
+
+  n-cli run --log failure make build
+  n-cli logs last
+
+Use --heartbeat and --stall-after for long-running jobs:
+
+  n-cli run --heartbeat 30m --stall-after 10m ./train-model.sh
+
+Use --timeout to stop commands that run for too long. The command and everything it started get --kill-signal, then SIGKILL after --kill-after:
+
+  n-cli run --timeout 45m --kill-after 30s make integration-test
+
+SIGINT, SIGTERM, SIGHUP and SIGQUIT are forwarded to the command and everything it started. You still get an "INTERRUPTED by SIGINT" notification, and n-cli exits with 128+signal (130 for SIGINT).
+
+Use --notify-on to get notified when the output matches a regular expression, e.g. for dev servers or prompts waiting for input. Use --notify-on-then to stop the command on the first match:
+
+  n-cli run --notify-on "Compiled successfully" --notify-on "Enter a value:" terraform apply
+  n-cli run --notify-on "panic:" --notify-on-then stop ./server
+
+Use --sample-interval to sample CPU, memory, threads and I/O of the command and everything it starts (Linux only). The notification then includes a summary, and --metrics-out writes the whole time series as JSON:
+
+  n-cli run --sample-interval 2s --metrics-out build-metrics.json make -j8
+
+Use --cgroup to run the command in its own cgroup v2 (Linux only). The notification then accounts for everything the command started, even daemons, and reports OOM kills. --memory-max and --cpu-max add limits:
+
+  n-cli run --memory-max 4G --cpu-max 2 make -j8
+
+Every run is recorded in ~/.n-cli/history.jsonl (disable with --no-history). Once a command has succeeded 3 times in the same directory, the notification compares the run to its usual duration. Use --notify-if-slower to only get notified when a run is abnormally slow, and n-cli stats to see your slowest commands:
+
+  n-cli run --notify-if-slower 1.5x make build
+
+Use --eta to print when the command is expected to finish, based on its usual duration, and --eta-notify-at to get an "about 80% done" notification:
+
+  n-cli run --eta-notify-at 80 make build
+
+Use --retry for flaky commands. You get a single notification at the end, with the exit code and duration of every attempt:
+
+  n-cli run --retry 3 --retry-delay 30s --retry-on-exit 1,137 make integration-test
+
+Use --shell to run the command line through your shell ($SHELL, or run.shell in your config), so that pipes, && and globs work. Pass the command line as a single argument; several arguments are quoted for your shell and reach the command unchanged (cmd.exe only takes a single argument):
+
+  n-cli run --shell "make && make test"
 
 Do note that some metrics may be missing on Windows (open a PR if you are interested in implementing them).
 `,
 		Run: func(cobraCmd *cobra.Command, args []string) {
-			command := args[0]
-			commandArgs := args[1:]
+			cfg, err := config.GetConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: Cannot read config: %s\n", err.Error())
+			}
+			runCfg := config.RunConfig{}
+			if cfg.Run != nil {
+				runCfg = *cfg.Run
+			}
 
-			cmd := exec.Command(command, commandArgs...)
-			cmd.Stdin = os.Stdin
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
+			args = runner.TrimSeparator(args)
+			historyCommand := history.NormalizeCommand(runner.CommandLine(args))
+			if useShell {
+				shell := runner.ResolveShell(runCfg.Shell)
+				commandLine, err := runner.ShellCommandLine(shell, args)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR: --shell: %s\n", err.Error())
+					os.Exit(1)
+				}
+				args = runner.ShellArgs(shell, commandLine)
+				markerOpts.DisplayCommand = commandLine
+			}
+			if !cobraCmd.Flags().Changed("tail") {
+				tailLines = runCfg.Tail
+			}
+			if !cobraCmd.Flags().Changed("tail-stderr") {
+				tailStderrOnly = runCfg.TailStderrOnly
+			}
+			if !cobraCmd.Flags().Changed("pty") {
+				usePTY = runCfg.PTY
+			}
+			if usePTY && !runner.PTYSupported {
+				fmt.Fprintf(os.Stderr, "ERROR: --pty: %s\n", runner.ErrPTYNotSupported.Error())
+				os.Exit(1)
+			}
+			if usePTY && tailStderrOnly {
+				fmt.Fprintln(os.Stderr, "ERROR: --tail-stderr cannot be used with --pty, since stdout and stderr are the same terminal")
+				os.Exit(1)
+			}
+			if !cobraCmd.Flags().Changed("min-duration") {
+				markerOpts.MinDuration = runCfg.MinDuration
+			}
+			if !cobraCmd.Flags().Changed("on") {
+				notifyOn = runCfg.NotifyOn
+			}
+			if !cobraCmd.Flags().Changed("success-exit-codes") && len(runCfg.SuccessExitCodes) > 0 {
+				markerOpts.SuccessExitCodes = runCfg.SuccessExitCodes
+			}
+			markerOpts.NotifyOn, err = marker.ParseNotifyOn(notifyOn)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: --on: %s\n", err.Error())
+				os.Exit(1)
+			}
+			if !cobraCmd.Flags().Changed("notify-if-slower") {
+				notifyIfSlower = runCfg.NotifyIfSlower
+			}
+			if !cobraCmd.Flags().Changed("no-history") {
+				noHistory = runCfg.DisableHistory
+			}
+			if !cobraCmd.Flags().Changed("eta") {
+				markerOpts.ETA = runCfg.ETA
+			}
+			if etaNotifyAt < 0 || etaNotifyAt >= 100 {
+				fmt.Fprintln(os.Stderr, "ERROR: --eta-notify-at: expected a percentage between 1 and 99")
+				os.Exit(1)
+			}
+			if etaNotifyAt > 0 {
+				markerOpts.ETA = true
+				markerOpts.ETANotifyAt = float64(etaNotifyAt) / 100
+			}
+			if markerOpts.ETA && noHistory {
+				etaFlag := cobraCmd.Flags().Changed("eta") || cobraCmd.Flags().Changed("eta-notify-at")
+				if etaFlag && cobraCmd.Flags().Changed("no-history") {
+					fmt.Fprintln(os.Stderr, "ERROR: --eta needs the run history, which is disabled")
+					os.Exit(1)
+				}
+				// the config may set both, which shouldn't keep every run from starting
+				fmt.Fprintln(os.Stderr, "WARN: The ETA needs the run history, which is disabled; skipping it")
+				markerOpts.ETA = false
+				markerOpts.ETANotifyAt = 0
+			}
+			if notifyIfSlower != "" {
+				if noHistory {
+					fmt.Fprintln(os.Stderr, "ERROR: --notify-if-slower needs the run history, which is disabled")
+					os.Exit(1)
+				}
+				markerOpts.NotifyIfSlower, err = formatter.ParseFactor(notifyIfSlower)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR: --notify-if-slower: %s\n", err.Error())
+					os.Exit(1)
+				}
+			}
+			cwd, _ := os.Getwd()
+			var store *history.Store
+			var pastRuns int
+			if !noHistory {
+				store, markerOpts.Baseline, pastRuns = loadHistory(historyCommand, cwd)
+			}
+			if markerOpts.NotifyIfSlower > 0 && markerOpts.Baseline == nil {
+				fmt.Fprintf(os.Stderr, "n-cli run: not enough history for --notify-if-slower yet (it needs %d successful runs of this command here), so there will be no notification\n", history.MinRuns)
+			}
+
+			for _, pattern := range patterns {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR: --notify-on: %s\n", err.Error())
+					os.Exit(1)
+				}
+				markerOpts.Patterns = append(markerOpts.Patterns, re)
+			}
+			if !slices.Contains([]string{patternThenContinue, patternThenStop, patternThenExit}, patternThen) {
+				fmt.Fprintf(os.Stderr, "ERROR: --notify-on-then: expected %s, %s or %s\n", patternThenContinue, patternThenStop, patternThenExit)
+				os.Exit(1)
+			}
+			ctx, stopOnMatch := context.WithCancel(context.Background())
+			defer stopOnMatch()
+			matched := make(chan string, 1)
+			if patternThen != patternThenContinue {
+				markerOpts.OnPatternMatch = func(pattern string) {
+					matched <- pattern
+					stopOnMatch()
+				}
+			}
 
-			defer func() {
-				os.Exit(cmd.ProcessState.ExitCode())
-			}()
+			if metricsOut != "" && sampleInterval <= 0 {
+				sampleInterval = time.Second
+			}
+			if sampleInterval > 0 {
+				markerOpts.Sampler = monitor.NewSampler(sampleInterval)
+			}
 
-			m := marker.NewNotificationMarker(cmd)
-			defer m.Done()
+			sig, err := runner.ParseSignal(killSignal)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: --kill-signal: %s\n", err.Error())
+				os.Exit(1)
+			}
 
-			err := cmd.Run()
+			cg, err := setUpCgroup(useCgroup, cgroupParent, memoryMax, cpuMax)
 			if err != nil {
-				fmt.Printf("n-cli run error: %s\n", err.Error())
+				fmt.Fprintf(os.Stderr, "ERROR: --cgroup: %s\n", err.Error())
+				os.Exit(1)
+			}
+			markerOpts.Cgroup = cg
+
+			var stdoutTaps, stderrTaps []io.Writer
+			if !cobraCmd.Flags().Changed("log") {
+				logPolicy = runCfg.Log
+			}
+			var log *runlog.Log
+			if logPolicy != "" {
+				log, err = createRunLog(logPolicy, runCfg, historyCommand)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR: --log: %s\n", err.Error())
+					if cg != nil {
+						cg.Close()
+					}
+					os.Exit(1)
+				}
+				markerOpts.Log = log
+				stdoutTaps = append(stdoutTaps, log)
+				stderrTaps = append(stderrTaps, log)
+			}
+
+			var m marker.NotificationMarker
+			if tailLines > 0 {
+				tail := capture.NewLineBuffer(tailLines)
+				markerOpts.OutputTail = tail
+				markerOpts.OutputTailSource = "output"
+				if tailStderrOnly {
+					markerOpts.OutputTailSource = "stderr"
+				} else {
+					stdoutTaps = append(stdoutTaps, tail.Writer())
+				}
+				stderrTaps = append(stderrTaps, tail.Writer())
+			}
+			if markerOpts.StallAfter > 0 {
+				activity := capture.NewActivity()
+				markerOpts.Activity = activity
+				stdoutTaps = append(stdoutTaps, activity.Writer())
+				stderrTaps = append(stderrTaps, activity.Writer())
+			}
+			if len(markerOpts.Patterns) > 0 {
+				scan := func(line string) { m.ScanLine(line) }
+				stdoutTaps = append(stdoutTaps, capture.NewLineWriter(scan))
+				stderrTaps = append(stderrTaps, capture.NewLineWriter(scan))
 			}
+			// only wrap the streams we need, since wrapped streams are no longer
+			// TTYs (unless the command runs in a pseudo-terminal)
+			stdout := withTaps(os.Stdout, stdoutTaps)
+			stderr := withTaps(os.Stderr, stderrTaps)
+			newCmd := func() *exec.Cmd {
+				cmd := exec.Command(args[0], args[1:]...)
+				cmd.Stdin = os.Stdin
+				cmd.Stdout = stdout
+				cmd.Stderr = stderr
+				if cg != nil {
+					cg.Attach(cmd)
+				}
+				return cmd
+			}
+
+			maxAttempts := max(retries, 1)
+			if maxAttempts > 1 {
+				markerOpts.MaxAttempts = maxAttempts
+			}
+
+			cmd := newCmd()
+			m = marker.NewNotificationMarker(cmd, markerOpts)
+			var result runner.Result
+			var attemptStartedFrom time.Time
+			for attempt := 1; ; attempt++ {
+				attemptStartedFrom = time.Now()
+				result = runAttempt(ctx, cmd, timeout, runner.Options{
+					KillSignal: sig,
+					KillAfter:  killAfter,
+					OnStart:    m.Start,
+					PTY:        usePTY,
+				})
+				exitCode := runner.ExitCode(cmd, result)
+				if attempt >= maxAttempts || ctx.Err() != nil || !shouldRetry(cmd, result, exitCode, markerOpts.SuccessExitCodes, retryOnExit) {
+					break
+				}
+				fmt.Fprintf(os.Stderr, "n-cli run: attempt %d/%d exited with %d, retrying in %s\n", attempt, maxAttempts, exitCode, retryDelay)
+				elapsed := time.Since(attemptStartedFrom)
+				if interrupted := runner.Sleep(retryDelay); interrupted != nil {
+					result = runner.Result{Signal: interrupted}
+					break
+				}
+				cmd = newCmd()
+				m.Retry(exitCode, elapsed, cmd)
+				if log != nil {
+					fmt.Fprintf(log, "\n--- n-cli: attempt %d/%d ---\n", attempt+1, maxAttempts)
+				}
+			}
+			lastAttempt := time.Since(attemptStartedFrom)
+			pattern := ""
+			select {
+			case pattern = <-matched:
+			default:
+			}
+			if pattern != "" && patternThen == patternThenExit {
+				// the match was all we were waiting for
+				writeMetrics(metricsOut, markerOpts.Sampler, args)
+				if cg != nil {
+					cg.Close()
+				}
+				closeRunLog(log, true, runCfg)
+				os.Exit(0)
+			}
+
+			// interrupted runs say nothing about how long the command takes
+			record := store != nil && cmd.ProcessState != nil
+			stopped := true
+			if sig, ok := runner.Interrupted(cmd, result); ok {
+				fmt.Fprintf(os.Stderr, "n-cli run: interrupted by %s\n", runner.SignalName(sig))
+				m.Stopped("INTERRUPTED by " + runner.SignalName(sig))
+				record = false
+			} else if pattern != "" && result.Stopped {
+				m.Stopped(fmt.Sprintf("STOPPED after output matched `%s`", pattern))
+			} else if oomKilled(cmd, cg) {
+				fmt.Fprintln(os.Stderr, "n-cli run: killed by the OOM killer")
+				m.Stopped("KILLED by the OOM killer")
+			} else if result.TimedOut {
+				fmt.Fprintf(os.Stderr, "n-cli run: timed out after %s\n", timeout)
+				m.Stopped(fmt.Sprintf("TIMED OUT after %s", timeout))
+			} else {
+				stopped = false
+				if result.Err != nil {
+					fmt.Printf("n-cli run error: %s\n", result.Err.Error())
+				}
+				if cmd.ProcessState == nil && result.Err != nil {
+					m.Stopped("FAILED to start: " + result.Err.Error())
+				}
+			}
+			exitCode := runner.ExitCode(cmd, result)
+			succeeded := !stopped && cmd.ProcessState != nil && slices.Contains(markerOpts.SuccessExitCodes, exitCode)
+			m.Done()
+			if record {
+				recordRun(store, pastRuns, history.Entry{
+					Time:      attemptStartedFrom,
+					Command:   historyCommand,
+					Cwd:       cwd,
+					ExitCode:  exitCode,
+					Succeeded: succeeded,
+					Elapsed:   lastAttempt,
+				}, cmd.ProcessState)
+			}
+			writeMetrics(metricsOut, markerOpts.Sampler, args)
+			if cg != nil {
+				cg.Close()
+			}
+			closeRunLog(log, succeeded, runCfg)
+			os.Exit(exitCode)
 		},
 	}
+	// everything after the command belongs to the command, not to n-cli
+	c.Flags().SetInterspersed(false)
+	c.Flags().BoolVar(&useShell, "shell", false, "Run the command line through your shell (run.shell in config, $SHELL, or /bin/sh)")
+	c.Flags().BoolVar(&usePTY, "pty", false, "Run the command in a pseudo-terminal, so that it keeps colours and interactivity while its output is captured (Linux and macOS, run.pty in config)")
+	c.Flags().IntVar(&tailLines, "tail", 0, "Add the last N lines of output to the notification when the command fails (run.tail in config)")
+	c.Flags().DurationVar(&markerOpts.MinDuration, "min-duration", 0, "Only notify if the command takes at least this long, e.g. 30s (run.minDuration in config)")
+	c.Flags().StringVar(&notifyOn, "on", string(marker.NotifyOnAlways), "When to notify: always, success or failure (run.notifyOn in config)")
+	c.Flags().IntSliceVar(&markerOpts.SuccessExitCodes, "success-exit-codes", []int{0}, "Exit codes that count as success, e.g. 0,1 for grep (run.successExitCodes in config)")
+	c.Flags().BoolVar(&tailStderrOnly, "tail-stderr", false, "Only capture stderr for --tail (run.tailStderrOnly in config)")
+	c.Flags().DurationVar(&timeout, "timeout", 0, "Stop the command if it runs longer than this, e.g. 45m. n-cli then exits with 124, like coreutils timeout")
+	c.Flags().StringVar(&killSignal, "kill-signal", "TERM", "Signal sent to the command's process group on --timeout")
+	c.Flags().DurationVar(&killAfter, "kill-after", 10*time.Second, "Send SIGKILL if the command is still running this long after --kill-signal (0 waits forever)")
+	c.Flags().StringArrayVar(&patterns, "notify-on", nil, "Send a notification when a line of output matches this regular expression (repeatable)")
+	c.Flags().DurationVar(&markerOpts.PatternDebounce, "notify-on-debounce", 30*time.Second, "Minimum time between two notifications for the same --notify-on pattern")
+	c.Flags().StringVar(&patternThen, "notify-on-then", patternThenContinue, "What to do after the first --notify-on match: continue, stop (stop the command) or exit (stop the command, n-cli exits with 0)")
+	c.Flags().DurationVar(&sampleInterval, "sample-interval", 0, "Sample CPU, memory, threads and I/O of the command and its children at this interval, e.g. 1s (Linux only)")
+	c.Flags().StringVar(&metricsOut, "metrics-out", "", "Write the sampled time series to this JSON file (implies --sample-interval 1s)")
+	c.Flags().BoolVar(&useCgroup, "cgroup", false, "Run the command in its own cgroup v2 for exact accounting of everything it starts (Linux only)")
+	c.Flags().StringVar(&cgroupParent, "cgroup-parent", "", "Create the cgroup in this directory (default: n-cli.slice under your systemd user manager; implies --cgroup)")
+	c.Flags().StringVar(&memoryMax, "memory-max", "", "Memory limit for the cgroup, e.g. 4G (implies --cgroup)")
+	c.Flags().Float64Var(&cpuMax, "cpu-max", 0, "CPU limit for the cgroup in CPUs, e.g. 1.5 (implies --cgroup)")
+	c.Flags().StringVar(&notifyIfSlower, "notify-if-slower", "", "Only notify when the run takes at least this many times its usual duration, e.g. 1.5x (run.notifyIfSlower in config)")
+	c.Flags().BoolVar(&markerOpts.ETA, "eta", false, "Print when the command is expected to finish, based on the median of its previous runs in this directory (run.eta in config)")
+	c.Flags().IntVar(&etaNotifyAt, "eta-notify-at", 0, "Send an \"about N% done\" notification based on the previous runs, e.g. 80 (implies --eta)")
+	c.Flags().BoolVar(&noHistory, "no-history", false, "Do not record this run in ~/.n-cli/history.jsonl (run.disableHistory in config)")
+	c.Flags().StringVar(&logPolicy, "log", "", "Save the complete output to ~/.n-cli/logs: failure (keep it only if the command fails) or always (run.log in config)")
+	c.Flags().IntVar(&retries, "retry", 0, "Run the command up to N times in total until it succeeds, e.g. 3")
+	c.Flags().DurationVar(&retryDelay, "retry-delay", 0, "How long to wait between attempts, e.g. 30s")
+	c.Flags().IntSliceVar(&retryOnExit, "retry-on-exit", nil, "Only retry on these exit codes, e.g. 1,137 (default: any failure)")
+	c.Flags().DurationVar(&markerOpts.Heartbeat, "heartbeat", 0, "Send a \"still running\" notification at this interval, e.g. 30m")
+	c.Flags().DurationVar(&markerOpts.StallAfter, "stall-after", 0, "Send a notification when the command has not written any output for this long, e.g. 10m")
+	return c
+}
+
+// What to do after the first --notify-on match.
+const (
+	patternThenContinue = "continue"
+	patternThenStop     = "stop"
+	patternThenExit     = "exit"
+)
+
+// runAttempt runs cmd once, stopping it after timeout if that is set.
+func runAttempt(ctx context.Context, cmd *exec.Cmd, timeout time.Duration, opts runner.Options) runner.Result {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return runner.Run(ctx, cmd, opts)
+}
+
+// shouldRetry reports whether a finished attempt is worth running again:
+// it failed, was not interrupted, and (if retryOnExit is set) exited with one
+// of the listed codes.
+func shouldRetry(cmd *exec.Cmd, result runner.Result, exitCode int, successExitCodes, retryOnExit []int) bool {
+	if _, interrupted := runner.Interrupted(cmd, result); interrupted {
+		return false
+	}
+	if cmd.ProcessState == nil || slices.Contains(successExitCodes, exitCode) {
+		return false
+	}
+	return len(retryOnExit) == 0 || slices.Contains(retryOnExit, exitCode)
+}
+
+// setUpCgroup creates the cgroup for the command, if one is wanted.
+func setUpCgroup(useCgroup bool, parent, memoryMax string, cpuMax float64) (*cgroup.Cgroup, error) {
+	if !useCgroup && parent == "" && memoryMax == "" && cpuMax <= 0 {
+		return nil, nil
+	}
+	limits := cgroup.Limits{CPUMax: cpuMax}
+	if memoryMax != "" {
+		var err error
+		limits.MemoryMax, err = formatter.ParseByteSize(memoryMax)
+		if err != nil {
+			return nil, fmt.Errorf("--memory-max: %w", err)
+		}
+	}
+	if parent == "" {
+		var err error
+		parent, err = cgroup.DefaultParent()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cgroup.New(parent, fmt.Sprintf("run-%d.scope", os.Getpid()), limits)
+}
+
+// loadHistory opens the run history and returns the usual duration of command
+// in cwd, along with how many runs the history holds. History is best-effort:
+// on errors, the store is nil.
+func loadHistory(command, cwd string) (*history.Store, *history.Baseline, int) {
+	path, err := history.DefaultPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Cannot find the run history: %s\n", err.Error())
+		return nil, nil, 0
+	}
+	store := history.NewStore(path)
+	entries, err := store.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Cannot read the run history: %s\n", err.Error())
+		return nil, nil, 0
+	}
+	return store, history.BaselineFor(entries, command, cwd), len(entries)
+}
+
+// recordRun adds a run to the history, with the resource usage of state.
+func recordRun(store *history.Store, pastRuns int, entry history.Entry, state *os.ProcessState) {
+	if usage, err := monitor.GetUsage(state); err == nil {
+		entry.CPUTime = usage.CPUTime()
+		entry.MaxRSS = usage.MaxRSS
+	}
+	err := store.Append(entry)
+	if err == nil && history.NeedsTrim(pastRuns+1) {
+		err = store.Trim(history.MaxEntries)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Cannot record the run history: %s\n", err.Error())
+	}
+}
+
+// Defaults for run.logMaxFiles and run.logMaxSize.
+const (
+	defaultLogMaxFiles = 100
+	defaultLogMaxSize  = "1G"
+)
+
+func createRunLog(policy string, runCfg config.RunConfig, command string) (*runlog.Log, error) {
+	p, err := runlog.ParsePolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := runlog.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	log, err := runlog.Create(dir, command, time.Now(), p)
+	if err != nil {
+		return nil, err
+	}
+	log.BaseURL = runCfg.LogBaseURL
+	return log, nil
+}
+
+// closeRunLog closes the log, which removes it if it isn't worth keeping, and
+// prunes old logs.
+func closeRunLog(log *runlog.Log, succeeded bool, runCfg config.RunConfig) {
+	if log == nil {
+		return
+	}
+	if err := log.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: The log is incomplete: %s\n", err.Error())
+	}
+	if err := log.Close(succeeded); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Cannot save the log: %s\n", err.Error())
+		return
+	}
+	if !log.Keep(succeeded) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "n-cli run: output saved to %s\n", log.Path)
+	retention := runlog.Retention{MaxFiles: runCfg.LogMaxFiles}
+	if retention.MaxFiles == 0 {
+		retention.MaxFiles = defaultLogMaxFiles
+	}
+	maxSize := runCfg.LogMaxSize
+	if maxSize == "" {
+		maxSize = defaultLogMaxSize
+	}
+	var err error
+	if retention.MaxBytes, err = formatter.ParseByteSize(maxSize); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: run.logMaxSize: %s\n", err.Error())
+		return
+	}
+	if err := runlog.Prune(filepath.Dir(log.Path), retention); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Cannot remove old logs: %s\n", err.Error())
+	}
+}
+
+// oomKilled reports whether the command itself was killed by the OOM killer.
+func oomKilled(cmd *exec.Cmd, cg *cgroup.Cgroup) bool {
+	if cg == nil || cmd.ProcessState == nil {
+		return false
+	}
+	if sig, ok := runner.TerminatingSignal(cmd.ProcessState); !ok || sig != 9 {
+		return false
+	}
+	stats, err := cg.Stats()
+	return err == nil && stats.OOMKills > 0
+}
+
+// metricsFile is the format of --metrics-out.
+type metricsFile struct {
+	Command []string              `json:"command"`
+	Summary monitor.SampleSummary `json:"summary"`
+	Samples []monitor.Sample      `json:"samples"`
+}
+
+func writeMetrics(path string, sampler *monitor.Sampler, command []string) {
+	if path == "" || sampler == nil {
+		return
+	}
+	sampler.Stop()
+	samples := sampler.Samples()
+	b, err := json.MarshalIndent(metricsFile{
+		Command: command,
+		Summary: monitor.Summarize(samples),
+		Samples: samples,
+	}, "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, b, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Cannot write --metrics-out: %s\n", err.Error())
+	}
+}
+
+// withTaps copies everything written to out into taps as well.
+func withTaps(out io.Writer, taps []io.Writer) io.Writer {
+	if len(taps) == 0 {
+		return out
+	}
+	return io.MultiWriter(append([]io.Writer{out}, taps...)...)
 }
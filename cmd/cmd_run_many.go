@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/lba-studio/n-cli/pkg/notifier"
+	"github.com/lba-studio/n-cli/pkg/notifier/marker"
+	"github.com/lba-studio/n-cli/pkg/parallel"
+	"github.com/lba-studio/n-cli/pkg/runner"
+	"github.com/spf13/cobra"
+)
+
+func NewRunManyCmd() *cobra.Command {
+	var jobs int
+	var file string
+	var opts parallel.Options
+	var notifyOn string
+	c := &cobra.Command{
+		Use:   "run-many",
+		Short: "Run several commands in parallel and get one summary notification.",
+		Long: `Runs several command lines through your shell ($SHELL, or run.shell in your config), --jobs at a time. Each line of their output starts with the command's number. Once they are all done, n-cli sends a single notification with the status, duration and peak memory of each command.
+
+Example: n-cli run-many "make lint" "make test" "make docs"
+Example: n-cli run-many --jobs 2 --fail-fast --file checks.txt
+
+With --file, every non-empty line that doesn't start with # is a command ("-" reads the commands from stdin).
+
+With --fail-fast, the first failure stops the other commands, and the remaining ones are skipped. Otherwise, every command runs.
+
+n-cli exits with the exit code of the first command that failed, or 0 if none did.
+`,
+		Run: func(cobraCmd *cobra.Command, args []string) {
+			cfg, err := config.GetConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: Cannot read config: %s\n", err.Error())
+			}
+			runCfg := config.RunConfig{}
+			if cfg.Run != nil {
+				runCfg = *cfg.Run
+			}
+			on, err := marker.ParseNotifyOn(notifyOn)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: --on: %s\n", err.Error())
+				os.Exit(1)
+			}
+
+			commandLines := args
+			if file != "" {
+				fromFile, err := readCommandFile(file)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR: --file: %s\n", err.Error())
+					os.Exit(1)
+				}
+				commandLines = append(commandLines, fromFile...)
+			}
+			if len(commandLines) == 0 {
+				fmt.Fprintln(os.Stderr, "ERROR: pass the commands as arguments, or with --file")
+				os.Exit(1)
+			}
+			shell := runner.ResolveShell(runCfg.Shell)
+			var batch []parallel.Job
+			for i, commandLine := range commandLines {
+				batch = append(batch, parallel.Job{Command: commandLine, Args: runner.ShellArgs(shell, commandLine)})
+				fmt.Fprintf(os.Stderr, "%s%s\n", parallel.Prefix(i, len(commandLines)), commandLine)
+			}
+
+			opts.Concurrency = jobs
+			opts.Stdout = os.Stdout
+			opts.Stderr = os.Stderr
+			startedFrom := time.Now()
+			results := parallel.Run(context.Background(), batch, opts)
+			summary := parallel.FormatSummary(results, time.Since(startedFrom))
+			exitCode := parallel.ExitCode(results)
+			fmt.Fprintf(os.Stderr, "\n%s\n", summary)
+
+			succeeded := exitCode == 0
+			if on == marker.NotifyOnAlways || on == marker.NotifyOnSuccess && succeeded || on == marker.NotifyOnFailure && !succeeded {
+				if err := notifier.Notify(summary); err != nil {
+					fmt.Printf("Error encountered when sending notification: %s\n", err.Error())
+				}
+			}
+			os.Exit(exitCode)
+		},
+	}
+	c.Flags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "How many commands run at once")
+	c.Flags().StringVarP(&file, "file", "f", "", "Read commands from this file, one per line (- for stdin)")
+	c.Flags().BoolVar(&opts.FailFast, "fail-fast", false, "Stop the other commands as soon as one fails")
+	c.Flags().StringVar(&notifyOn, "on", string(marker.NotifyOnAlways), "When to notify: always, success or failure")
+	c.Flags().IntSliceVar(&opts.SuccessExitCodes, "success-exit-codes", []int{0}, "Exit codes that count as success, e.g. 0,1 for grep")
+	c.Flags().DurationVar(&opts.KillAfter, "kill-after", 10*time.Second, "With --fail-fast, send SIGKILL to commands still running this long after SIGTERM (0 waits forever)")
+	return c
+}
+
+// readCommandFile returns the commands in path, skipping blank lines and
+// # comments.
+func readCommandFile(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var commandLines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		commandLines = append(commandLines, line)
+	}
+	return commandLines, scanner.Err()
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/lba-studio/n-cli/pkg/notifier"
+	"github.com/lba-studio/n-cli/pkg/shellhook"
+	"github.com/lba-studio/n-cli/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+// shellCooldownState remembers when n-cli shell-event last sent a notification.
+const shellCooldownState = "shell-cooldown"
+
+type shellCooldown struct {
+	LastNotified time.Time `json:"lastNotified"`
+}
+
+func NewShellInitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:       "shell-init bash|zsh|fish",
+		Short:     "Print shell hooks that notify you when a command takes long.",
+		ValidArgs: shellhook.Shells,
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		Long: `Prints hook code for your shell. Once it is loaded, any interactive command that takes longer than shellIntegration.threshold in your config (default: 30s) sends a notification with the command, its exit status and how long it took. No need to prefix it with n-cli run.
+
+  # ~/.bashrc
+  eval "$(n-cli shell-init bash)"
+  # ~/.zshrc
+  eval "$(n-cli shell-init zsh)"
+  # ~/.config/fish/config.fish
+  n-cli shell-init fish | source
+
+Commands interrupted with Ctrl-C or suspended with Ctrl-Z are not notified about, and neither are interactive programs such as vim, ssh or less (see shellIntegration.ignore). Use shellIntegration.cooldown to limit how often you get notified.
+
+The threshold is read when the hooks are loaded, so open a new shell after changing it. In bash, the hooks use the DEBUG trap (a trap that is already set keeps running) and PROMPT_COMMAND, or bash-preexec if it is loaded first.
+`,
+		Run: func(cobraCmd *cobra.Command, args []string) {
+			script, err := shellhook.Script(args[0], shellOptions().Threshold)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+				os.Exit(1)
+			}
+			fmt.Print(script)
+		},
+	}
+}
+
+func NewShellEventCmd() *cobra.Command {
+	var exitCode int
+	var duration time.Duration
+	c := &cobra.Command{
+		Use:    "shell-event -- <command>",
+		Short:  "Called by the hooks from n-cli shell-init when a command finishes.",
+		Hidden: true,
+		Args:   cobra.MinimumNArgs(1),
+		Run: func(cobraCmd *cobra.Command, args []string) {
+			opts := shellOptions()
+			event := shellhook.Event{
+				Command:  strings.Join(args, " "),
+				ExitCode: exitCode,
+				Duration: duration,
+			}
+			if cwd, err := os.Getwd(); err == nil {
+				home, _ := os.UserHomeDir()
+				event.Cwd = shortenHome(cwd, home)
+			}
+
+			var store *state.Store
+			var cooldown shellCooldown
+			if opts.Cooldown > 0 {
+				dir, err := state.DefaultDir()
+				if err == nil {
+					store = state.NewStore(dir)
+					err = store.Load(shellCooldownState, &cooldown)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "WARN: Cannot read the shell cooldown: %s\n", err.Error())
+				}
+			}
+			now := time.Now()
+			if !shellhook.ShouldNotify(event, opts, cooldown.LastNotified, now) {
+				return
+			}
+			if store != nil {
+				if err := store.Save(shellCooldownState, shellCooldown{LastNotified: now}); err != nil {
+					fmt.Fprintf(os.Stderr, "WARN: Cannot save the shell cooldown: %s\n", err.Error())
+				}
+			}
+			if err := notifier.NotifyTo(shellhook.FormatMessage(event), io.Discard); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+	c.Flags().IntVar(&exitCode, "exit-code", 0, "Exit code of the command")
+	c.Flags().DurationVar(&duration, "duration", 0, "How long the command took")
+	return c
+}
+
+// shellOptions reads the shellIntegration section of the config, with defaults.
+func shellOptions() shellhook.Options {
+	opts := shellhook.Options{
+		Threshold: shellhook.DefaultThreshold,
+		Ignore:    shellhook.DefaultIgnore,
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Cannot read config: %s\n", err.Error())
+	}
+	if cfg.ShellIntegration == nil {
+		return opts
+	}
+	if cfg.ShellIntegration.Threshold > 0 {
+		opts.Threshold = cfg.ShellIntegration.Threshold
+	}
+	if cfg.ShellIntegration.Ignore != nil {
+		opts.Ignore = cfg.ShellIntegration.Ignore
+	}
+	opts.Cooldown = cfg.ShellIntegration.Cooldown
+	return opts
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/formatter"
+	"github.com/lba-studio/n-cli/pkg/history"
+	"github.com/spf13/cobra"
+)
+
+const (
+	statsSortSlowest   = "slowest"
+	statsSortRegressed = "regressed"
+)
+
+// maxStatsCommandLength keeps long command lines from breaking the table.
+const maxStatsCommandLength = 60
+
+func NewStatsCmd() *cobra.Command {
+	var sortBy string
+	var limit int
+	var here bool
+	c := &cobra.Command{
+		Use:   "stats",
+		Short: "Show the slowest or most-regressed commands from the run history.",
+		Long: `Shows statistics about the commands you ran with n-cli run, from ~/.n-cli/history.jsonl.
+
+P50 and P90 are over successful runs. CHANGE compares the last successful run to the usual duration of the runs before it.
+
+Example: n-cli stats
+Example: n-cli stats --sort regressed --here
+`,
+		Args: cobra.NoArgs,
+		Run: func(cobraCmd *cobra.Command, args []string) {
+			if sortBy != statsSortSlowest && sortBy != statsSortRegressed {
+				fmt.Fprintf(os.Stderr, "ERROR: --sort: expected %s or %s\n", statsSortSlowest, statsSortRegressed)
+				os.Exit(1)
+			}
+			path, err := history.DefaultPath()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+				os.Exit(1)
+			}
+			entries, err := history.NewStore(path).Load()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: cannot read %s: %s\n", path, err.Error())
+				os.Exit(1)
+			}
+			stats := history.Summarize(entries)
+			if here {
+				cwd, _ := os.Getwd()
+				stats = slices.DeleteFunc(stats, func(s history.CommandStats) bool { return s.Cwd != cwd })
+			}
+			if len(stats) == 0 {
+				fmt.Println("No runs recorded yet. Commands run with n-cli run show up here.")
+				return
+			}
+			stats = sortStats(stats, sortBy)
+			if len(stats) == 0 {
+				fmt.Printf("No command has enough history yet; it takes %d successful runs before a run can be compared.\n", history.MinRuns+1)
+				return
+			}
+			if limit > 0 && len(stats) > limit {
+				stats = stats[:limit]
+			}
+			home, _ := os.UserHomeDir()
+			printStats(os.Stdout, stats, home)
+		},
+	}
+	c.Flags().StringVar(&sortBy, "sort", statsSortSlowest, "slowest (by p50) or regressed (by how much slower the last run was than usual)")
+	c.Flags().IntVarP(&limit, "limit", "n", 10, "Show at most this many commands (0 shows all)")
+	c.Flags().BoolVar(&here, "here", false, "Only show commands run in the current directory")
+	return c
+}
+
+// sortStats orders stats for --sort. Regressed only keeps commands with
+// enough history to tell.
+func sortStats(stats []history.CommandStats, sortBy string) []history.CommandStats {
+	if sortBy == statsSortRegressed {
+		stats = slices.DeleteFunc(stats, func(s history.CommandStats) bool { return s.Change == 0 })
+		slices.SortStableFunc(stats, func(a, b history.CommandStats) int {
+			return cmpDesc(a.Change, b.Change)
+		})
+		return stats
+	}
+	slices.SortStableFunc(stats, func(a, b history.CommandStats) int {
+		return cmpDesc(a.P50, b.P50)
+	})
+	return stats
+}
+
+func cmpDesc[T int64 | float64 | time.Duration](a, b T) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func printStats(out io.Writer, stats []history.CommandStats, home string) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COMMAND\tDIR\tRUNS\tFAILED\tP50\tP90\tLAST\tCHANGE")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			formatter.TruncateLine(s.Command, maxStatsCommandLength),
+			shortenHome(s.Cwd, home),
+			s.Runs,
+			s.Failures,
+			formatStatsDuration(s.P50),
+			formatStatsDuration(s.P90),
+			formatStatsDuration(s.Last),
+			formatChange(s.Change),
+		)
+	}
+	w.Flush()
+}
+
+func formatStatsDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	if d >= 10*time.Second {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+func formatChange(change float64) string {
+	switch {
+	case change == 0:
+		return "-"
+	case change >= 1:
+		return fmt.Sprintf("%.1f× slower", change)
+	default:
+		return fmt.Sprintf("%.1f× faster", 1/change)
+	}
+}
+
+// shortenHome replaces the home directory at the start of path with ~.
+func shortenHome(path, home string) string {
+	if home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return filepath.Join("~", rest)
+	}
+	return path
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/monitor"
+	"github.com/lba-studio/n-cli/pkg/notifier"
+	"github.com/lba-studio/n-cli/pkg/runner"
+	"github.com/lba-studio/n-cli/pkg/waiter"
+	"github.com/spf13/cobra"
+)
+
+func NewWaitCmd() *cobra.Command {
+	var pid int
+	var name, tcpAddr, httpUrl, bodyMatch, filePath, fileAbsentPath string
+	var statusCodes []int
+	var interval, timeout time.Duration
+	c := &cobra.Command{
+		Use:   "wait",
+		Short: "Wait for something to finish or become ready, then get notified.",
+		Long: `Waits for one condition, then sends a notification:
+
+  --pid / --name   an already-running process exits (Linux only for now). The notification includes how long it ran, its command line and its peak memory usage.
+  --tcp            host:port accepts connections
+  --http           a URL responds with a 2xx status (or one of --status), and its body matches --body-match if set
+  --file           a file is created, or changes if it already exists
+  --file-absent    a file is removed, e.g. a lock file
+
+Example: n-cli wait --pid 1234
+Example: n-cli wait --tcp localhost:5432 --timeout 5m
+Example: n-cli wait --http http://localhost:8080/health --body-match '"ok"'
+Example: n-cli wait --file-absent /var/lib/app/migration.lock
+
+With --timeout, you get a notification either way, and n-cli exits with 124 if the condition was not met in time.
+`,
+		Args: cobra.NoArgs,
+		Run: func(cobraCmd *cobra.Command, args []string) {
+			var set []string
+			for _, flag := range []string{"pid", "name", "tcp", "http", "file", "file-absent"} {
+				if cobraCmd.Flags().Changed(flag) {
+					set = append(set, flag)
+				}
+			}
+			if len(set) != 1 {
+				fmt.Fprintln(os.Stderr, "ERROR: pass exactly one of --pid, --name, --tcp, --http, --file or --file-absent")
+				os.Exit(1)
+			}
+			flag := set[0]
+			if flag == "pid" && pid <= 0 {
+				fmt.Fprintf(os.Stderr, "ERROR: --pid: expected a pid greater than 0, got %d\n", pid)
+				os.Exit(1)
+			}
+			if cobraCmd.Flags().Lookup(flag).Value.String() == "" {
+				fmt.Fprintf(os.Stderr, "ERROR: --%s: must not be empty\n", flag)
+				os.Exit(1)
+			}
+
+			if interval <= 0 {
+				fmt.Fprintf(os.Stderr, "ERROR: --interval: %s\n", waiter.ErrInvalidInterval.Error())
+				os.Exit(1)
+			}
+
+			var cond waiter.Condition
+			switch flag {
+			case "pid", "name":
+				if flag == "name" {
+					var err error
+					pid, err = findProcessByName(name)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+						os.Exit(1)
+					}
+				}
+				p, err := monitor.GetProcess(pid)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR: cannot watch pid %d: %s\n", pid, err.Error())
+					os.Exit(1)
+				}
+				cond = waiter.ProcessExit(p)
+			case "tcp":
+				cond = waiter.TCP(tcpAddr)
+			case "http":
+				opts := waiter.HTTPOptions{StatusCodes: statusCodes}
+				if bodyMatch != "" {
+					re, err := regexp.Compile(bodyMatch)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "ERROR: --body-match: %s\n", err.Error())
+						os.Exit(1)
+					}
+					opts.BodyRegex = re
+				}
+				cond = waiter.HTTP(httpUrl, opts)
+			case "file":
+				cond = waiter.File(filePath)
+			case "file-absent":
+				cond = waiter.FileAbsent(fileAbsentPath)
+			}
+
+			ctx := context.Background()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			fmt.Fprintf(os.Stderr, "n-cli wait: waiting for %s\n", cond.Describe())
+			startedFrom := time.Now()
+			err := waiter.Wait(ctx, cond, interval)
+			waited := time.Since(startedFrom).Round(time.Second)
+
+			exitCode := 0
+			var msg string
+			var notMet *waiter.NotMetError
+			if errors.As(err, &notMet) {
+				exitCode = runner.ExitCodeTimedOut
+				msg = fmt.Sprintf("TIMED OUT after %s waiting for %s.", timeout, cond.Describe())
+				if notMet.LastCheck != nil {
+					msg += fmt.Sprintf("\nLast check: %s", notMet.LastCheck.Error())
+				}
+				fmt.Fprintf(os.Stderr, "n-cli wait: timed out: %s\n", err.Error())
+			} else {
+				msg = fmt.Sprintf("%s\nWaited: %s", cond.ReadyMessage(), waited)
+			}
+			if err := notifier.Notify(msg); err != nil {
+				fmt.Printf("Error encountered when sending notification: %s\n", err.Error())
+			}
+			os.Exit(exitCode)
+		},
+	}
+	c.Flags().IntVar(&pid, "pid", 0, "Wait for the process with this pid to exit")
+	c.Flags().StringVar(&name, "name", "", "Wait for the process with this name to exit, e.g. cargo")
+	c.Flags().StringVar(&tcpAddr, "tcp", "", "Wait for host:port to accept connections")
+	c.Flags().StringVar(&httpUrl, "http", "", "Wait for a URL to respond with a 2xx status")
+	c.Flags().IntSliceVar(&statusCodes, "status", nil, "Status codes that count as ready for --http, e.g. 200,401")
+	c.Flags().StringVar(&bodyMatch, "body-match", "", "Regular expression the --http response body must match")
+	c.Flags().StringVar(&filePath, "file", "", "Wait for a file to be created, or to change if it already exists")
+	c.Flags().StringVar(&fileAbsentPath, "file-absent", "", "Wait for a file to be removed, e.g. a lock file")
+	c.Flags().DurationVar(&interval, "interval", time.Second, "How often to check")
+	c.Flags().DurationVar(&timeout, "timeout", 0, "Give up after this long, e.g. 10m (0 waits forever)")
+	return c
+}
+
+func findProcessByName(name string) (int, error) {
+	pids, err := monitor.FindProcesses(name)
+	if err != nil {
+		return 0, err
+	}
+	switch len(pids) {
+	case 0:
+		return 0, fmt.Errorf("no process named %q", name)
+	case 1:
+		return pids[0], nil
+	default:
+		var candidates []string
+		for _, pid := range pids {
+			if p, err := monitor.GetProcess(pid); err == nil {
+				candidates = append(candidates, fmt.Sprintf("  %d: %s", pid, waiter.ProcessCommand(p)))
+			}
+		}
+		return 0, fmt.Errorf("%d processes are named %q, pick one with --pid:\n%s", len(pids), name, strings.Join(candidates, "\n"))
+	}
+}
@@ -0,0 +1,67 @@
+package logs
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lba-studio/n-cli/pkg/runlog"
+	"github.com/spf13/cobra"
+)
+
+func NewLogsLastCmd() *cobra.Command {
+	var pathOnly bool
+	c := &cobra.Command{
+		Use:   "last",
+		Short: "Print the most recent log.",
+		Long: `Prints the most recent log saved by n-cli run --log.
+
+Example: n-cli logs last | less -R
+Example: vim "$(n-cli logs last --path)"
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			logs := listLogs()
+			if len(logs) == 0 {
+				fmt.Fprintln(os.Stderr, "No logs yet. Use n-cli run --log failure (or always) to save the output of your commands.")
+				os.Exit(1)
+			}
+			if pathOnly {
+				fmt.Println(logs[0].Path)
+				return
+			}
+			if err := printFile(os.Stdout, logs[0].Path); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+	c.Flags().BoolVar(&pathOnly, "path", false, "Only print the path of the log")
+	return c
+}
+
+func printFile(out io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(out, f)
+	return err
+}
+
+// listLogs returns the logs in the default directory, newest first, and
+// exits on errors.
+func listLogs() []runlog.Info {
+	dir, err := runlog.DefaultDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		os.Exit(1)
+	}
+	logs, err := runlog.List(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: cannot read %s: %s\n", dir, err.Error())
+		os.Exit(1)
+	}
+	return logs
+}
@@ -0,0 +1,43 @@
+package logs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/lba-studio/n-cli/pkg/formatter"
+	"github.com/lba-studio/n-cli/pkg/runlog"
+	"github.com/spf13/cobra"
+)
+
+func NewLogsListCmd() *cobra.Command {
+	var limit int
+	c := &cobra.Command{
+		Use:   "list",
+		Short: "List the most recent logs.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			logs := listLogs()
+			if len(logs) == 0 {
+				fmt.Println("No logs yet. Use n-cli run --log failure (or always) to save the output of your commands.")
+				return
+			}
+			if limit > 0 && len(logs) > limit {
+				logs = logs[:limit]
+			}
+			printLogs(os.Stdout, logs)
+		},
+	}
+	c.Flags().IntVarP(&limit, "limit", "n", 20, "Show at most this many logs (0 shows all)")
+	return c
+}
+
+func printLogs(out io.Writer, logs []runlog.Info) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODIFIED\tSIZE\tPATH")
+	for _, log := range logs {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", log.ModTime.Format("2006-01-02 15:04:05"), formatter.PrettyPrintBytes(log.Size), log.Path)
+	}
+	w.Flush()
+}
@@ -0,0 +1,22 @@
+package logs
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/runlog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintLogs(t *testing.T) {
+	var out bytes.Buffer
+	printLogs(&out, []runlog.Info{
+		{Path: "/home/me/.n-cli/logs/20261019-110203-make-build.log", Size: 2048, ModTime: time.Date(2026, 10, 19, 11, 5, 0, 0, time.Local)},
+		{Path: "/home/me/.n-cli/logs/20261018-090000-make-test.log", Size: 12, ModTime: time.Date(2026, 10, 18, 9, 0, 30, 0, time.Local)},
+	})
+	assert.Equal(t, `MODIFIED             SIZE     PATH
+2026-10-19 11:05:00  2.0 KiB  /home/me/.n-cli/logs/20261019-110203-make-build.log
+2026-10-18 09:00:30  12 B     /home/me/.n-cli/logs/20261018-090000-make-test.log
+`, out.String())
+}
@@ -0,0 +1,32 @@
+package remind
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/reminder"
+	"github.com/spf13/cobra"
+)
+
+func NewRemindAtCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "at <time> <message>",
+		Short: "Send yourself a notification at a given time, e.g. n-cli remind at 17:30 \"go home\".",
+		Long: `Sends you a notification the next time the local clock shows <time>: later today, or tomorrow if that time has already passed.
+
+Example: n-cli remind at 17:30 "go home"
+Example: n-cli remind at 9am standup
+`,
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			at, err := reminder.ParseClock(args[0], time.Now())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+				os.Exit(1)
+			}
+			Schedule(cmd, at, strings.Join(args[1:], " "))
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package remind
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/lba-studio/n-cli/pkg/reminder"
+	"github.com/spf13/cobra"
+)
+
+func NewRemindCancelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <id>...",
+		Short: "Cancel pending reminders.",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := openStore()
+			failed := false
+			for _, id := range args {
+				r, err := store.Get(id)
+				if err == nil {
+					err = store.Remove(id)
+				}
+				if errors.Is(err, reminder.ErrNotFound) {
+					fmt.Fprintf(os.Stderr, "ERROR: No pending reminder %s (see n-cli remind list)\n", id)
+					failed = true
+				} else if err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR: Cannot cancel reminder %s: %s\n", id, err.Error())
+					failed = true
+				} else {
+					stopWaiter(r)
+					fmt.Printf("Cancelled reminder %s.\n", id)
+				}
+			}
+			if failed {
+				os.Exit(1)
+			}
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package remind
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/reminder"
+	"github.com/spf13/cobra"
+)
+
+func NewRemindListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the pending reminders.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			store := openStore()
+			reminders, err := store.List()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: Cannot read the reminders: %s\n", err.Error())
+				os.Exit(1)
+			}
+			if len(reminders) == 0 {
+				fmt.Println("No pending reminders. Use n-cli remind 25m \"stand-up\" to set one.")
+				return
+			}
+			missed := printReminders(os.Stdout, reminders, time.Now(), waiterGone)
+			// they will never be sent, so they are only listed once
+			for _, r := range missed {
+				_ = store.Remove(r.ID)
+			}
+			if len(missed) > 0 {
+				fmt.Println("\nMissed reminders were not sent, e.g. because the machine was off, and are now removed.")
+			}
+		},
+	}
+}
+
+// overdueAfter is how late a reminder can be before it is reported as missed,
+// e.g. because the machine was off.
+const overdueAfter = 2 * waitPollInterval
+
+// printReminders returns the reminders it reported as missed: those that are
+// overdue, or whose background process is gone.
+func printReminders(out io.Writer, reminders []reminder.Reminder, now time.Time, waiterGone func(reminder.Reminder) bool) []reminder.Reminder {
+	var missed []reminder.Reminder
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDUE\tIN\tMESSAGE")
+	for _, r := range reminders {
+		in := formatIn(r.At.Sub(now))
+		if now.Sub(r.At) > overdueAfter || waiterGone(r) {
+			in = "missed"
+			missed = append(missed, r)
+		} else if !r.At.After(now) {
+			in = "now"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.ID, reminder.FormatDue(r.At, now), in, r.Message)
+	}
+	w.Flush()
+	return missed
+}
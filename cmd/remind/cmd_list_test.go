@@ -0,0 +1,42 @@
+package remind
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/reminder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintReminders(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)
+	var out bytes.Buffer
+	// the waiter of 778899 did not survive a reboot
+	missed := printReminders(&out, []reminder.Reminder{
+		{ID: "a1b2c3", Message: "missed it", At: now.Add(-time.Hour)},
+		{ID: "d4e5f6", Message: "due", At: now.Add(-10 * time.Second)},
+		{ID: "0a0b0c", Message: "tea", At: now.Add(45 * time.Second)},
+		{ID: "112233", Message: "stand-up", At: now.Add(25 * time.Minute)},
+		{ID: "778899", Message: "lunch", At: now.Add(3 * time.Hour)},
+		{ID: "445566", Message: "go home", At: now.Add(24 * time.Hour)},
+	}, now, func(r reminder.Reminder) bool { return r.ID == "778899" })
+	assert.Equal(t, `ID      DUE        IN      MESSAGE
+a1b2c3  08:00      missed  missed it
+d4e5f6  08:59      now     due
+0a0b0c  09:00      45s     tea
+112233  09:25      25m     stand-up
+778899  12:00      missed  lunch
+445566  Tue 09:00  24h     go home
+`, out.String())
+	assert.Len(t, missed, 2)
+	assert.Equal(t, "a1b2c3", missed[0].ID)
+	assert.Equal(t, "778899", missed[1].ID)
+}
+
+func TestFormatIn(t *testing.T) {
+	assert.Equal(t, "45s", formatIn(45*time.Second))
+	assert.Equal(t, "25m", formatIn(25*time.Minute+10*time.Second))
+	assert.Equal(t, "1h30m", formatIn(90*time.Minute))
+	assert.Equal(t, "8h", formatIn(8*time.Hour-20*time.Second))
+}
@@ -0,0 +1,49 @@
+package remind
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/notifier"
+	"github.com/lba-studio/n-cli/pkg/reminder"
+	"github.com/spf13/cobra"
+)
+
+// waitPollInterval bounds how late a reminder can be after the machine wakes
+// up from sleep, and how long a cancelled reminder's process lingers where n-cli cannot stop it.
+const waitPollInterval = 30 * time.Second
+
+// NewRemindWaitCmd is the background process started for each reminder.
+func NewRemindWaitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "wait <id>",
+		Hidden: true,
+		Args:   cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := openStore()
+			for {
+				r, err := store.Get(args[0])
+				if errors.Is(err, reminder.ErrNotFound) {
+					// cancelled
+					return
+				}
+				if err != nil {
+					os.Exit(1)
+				}
+				// At carries no monotonic clock reading, so this follows the
+				// wall clock, which keeps going while the machine sleeps
+				left := time.Until(r.At)
+				if left <= 0 {
+					// nobody sees our output; a failed notification stays in the list
+					if notifier.NotifyTo("Reminder: "+r.Message, io.Discard) == nil {
+						_ = store.Remove(r.ID)
+					}
+					return
+				}
+				time.Sleep(min(left, waitPollInterval))
+			}
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package remind
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/monitor"
+	"github.com/lba-studio/n-cli/pkg/reminder"
+	"github.com/lba-studio/n-cli/pkg/runner"
+	"github.com/spf13/cobra"
+)
+
+// Schedule saves a reminder and starts the background process that sends it.
+func Schedule(cmd *cobra.Command, at time.Time, message string) {
+	store := openStore()
+	now := time.Now()
+	// e.g. remind at 17:30 run at 17:29:59.9
+	if at.Sub(now) < reminder.MinDelay {
+		at = now.Add(reminder.MinDelay)
+	}
+	r, err := store.Add(reminder.Reminder{Message: message, At: at, Created: now})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Cannot save the reminder: %s\n", err.Error())
+		os.Exit(1)
+	}
+	pid, err := startWaiter(cmd, r.ID)
+	if err != nil {
+		_ = store.Remove(r.ID)
+		fmt.Fprintf(os.Stderr, "ERROR: Cannot start the reminder in the background: %s\n", err.Error())
+		os.Exit(1)
+	}
+	r.Pid = pid
+	// the waiter removes the reminder once it is sent (and cancel removes it
+	// too), and saving it after that would leave a ghost behind. MinDelay keeps
+	// the send well after this check.
+	if _, err := store.Get(r.ID); err == nil {
+		if err := store.Save(r); err != nil {
+			fmt.Fprintf(os.Stderr, "WARN: Cannot save the reminder: %s\n", err.Error())
+		}
+	}
+	fmt.Printf("Reminder %s set for %s (in %s). Cancel it with: n-cli remind cancel %s\n", r.ID, reminder.FormatDue(at, now), formatIn(at.Sub(now)), r.ID)
+}
+
+// startWaiter runs n-cli remind wait in a session of its own, so that it
+// outlives the shell.
+func startWaiter(cmd *cobra.Command, id string) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	args := []string{"remind", "wait", id}
+	if f := cmd.Flag("config"); f != nil && f.Value.String() != "" {
+		args = append(args, "--config", f.Value.String())
+	}
+	waiter := exec.Command(exe, args...)
+	runner.Detach(waiter)
+	if err := waiter.Start(); err != nil {
+		return 0, err
+	}
+	pid := waiter.Process.Pid
+	return pid, waiter.Process.Release()
+}
+
+// stopWaiter stops the background process of a cancelled reminder, which
+// would otherwise only notice within waitPollInterval. The pid may have been
+// reused since, so only a process that is recognisably the waiter is killed.
+func stopWaiter(r reminder.Reminder) {
+	if r.Pid == 0 {
+		return
+	}
+	p, err := monitor.GetProcess(r.Pid)
+	if err != nil || p.Exited() || !isWaiter(p, r.ID) {
+		return
+	}
+	if proc, err := os.FindProcess(r.Pid); err == nil {
+		_ = proc.Kill()
+	}
+}
+
+// waiterGone reports whether the background process of a reminder is known
+// to be gone, e.g. after a reboot, so that the reminder will never be sent.
+// It is false where n-cli cannot look at other processes.
+func waiterGone(r reminder.Reminder) bool {
+	if r.Pid == 0 {
+		return false
+	}
+	p, err := monitor.GetProcess(r.Pid)
+	if errors.Is(err, monitor.ErrCallNotSupported) || errors.Is(err, monitor.ErrIsWindows) {
+		return false
+	}
+	return err != nil || p.Exited() || !isWaiter(p, r.ID)
+}
+
+func isWaiter(p *monitor.Process, id string) bool {
+	return strings.Contains(p.Cmdline, " remind wait "+id)
+}
+
+func openStore() *reminder.Store {
+	dir, err := reminder.DefaultDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Cannot find the reminders: %s\n", err.Error())
+		os.Exit(1)
+	}
+	return reminder.NewStore(dir)
+}
+
+// formatIn formats how long until a reminder is due, to the minute once it is
+// more than a minute away.
+func formatIn(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	s := strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
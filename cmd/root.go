@@ -37,6 +37,14 @@ func init() {
 		NewRunCmd(),
 		NewSetupCmd(),
 		NewHookCmd(),
+		NewWaitCmd(),
+		NewStatsCmd(),
+		NewRunManyCmd(),
+		NewLogsCmd(),
+		NewCronCmd(),
+		NewRemindCmd(),
+		NewShellInitCmd(),
+		NewShellEventCmd(),
 	)
 }
 
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type DiscordConfig = WebhookConfig
 
 type SlackConfig = WebhookConfig
@@ -10,17 +12,114 @@ type WebhookConfig struct {
 }
 
 type CustomConfig struct {
-	Name            string            `mapstructure:"name" yaml:"name,omitempty"`
-	PayloadTemplate string            `mapstructure:"payloadTemplate"`
-	TargetUrl       string            `mapstructure:"targetUrl"`
-	Method          string            `mapstructure:"method"`
-	Headers         map[string]string `mapstructure:"headers"`
+	Name             string                `mapstructure:"name" yaml:"name,omitempty"`
+	PayloadTemplate  string                `mapstructure:"payloadTemplate"`
+	TargetUrl        string                `mapstructure:"targetUrl"`
+	Method           string                `mapstructure:"method"`
+	Headers          map[string]string     `mapstructure:"headers"`
+	Query            map[string]string     `mapstructure:"query"`
+	BodyType         string                `mapstructure:"bodyType"`
+	Form             map[string]string     `mapstructure:"form"`
+	Auth             *CustomAuthConfig     `mapstructure:"auth"`
+	Signing          *CustomSigningConfig  `mapstructure:"signing"`
+	TLS              *CustomTLSConfig      `mapstructure:"tls"`
+	Proxy            string                `mapstructure:"proxy"`
+	Response         *CustomResponseConfig `mapstructure:"response"`
+	MaxMessageLength int                   `mapstructure:"maxMessageLength"`
+}
+
+type CustomResponseConfig struct {
+	StatusCodes []int  `mapstructure:"statusCodes"`
+	JSONPath    string `mapstructure:"jsonPath"`
+	Equals      string `mapstructure:"equals"`
+	BodyRegex   string `mapstructure:"bodyRegex"`
+}
+
+const (
+	CustomBodyTypeForm      = "form"
+	CustomBodyTypeMultipart = "multipart"
+)
+
+type CustomTLSConfig struct {
+	CertFile           string `mapstructure:"certFile"`
+	KeyFile            string `mapstructure:"keyFile"`
+	CAFile             string `mapstructure:"caFile"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+}
+
+const (
+	CustomAuthBasic             = "basic"
+	CustomAuthBearer            = "bearer"
+	CustomAuthOAuth2Credentials = "oauth2"
+)
+
+type CustomAuthConfig struct {
+	Type string `mapstructure:"type"`
+	// basic
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// bearer
+	Token string `mapstructure:"token"`
+	// oauth2 (client credentials grant)
+	TokenUrl     string   `mapstructure:"tokenUrl"`
+	ClientId     string   `mapstructure:"clientId"`
+	ClientSecret string   `mapstructure:"clientSecret"`
+	Scopes       []string `mapstructure:"scopes"`
+	Audience     string   `mapstructure:"audience"`
+}
+
+type CustomSigningConfig struct {
+	Secret          string `mapstructure:"secret"`
+	Algorithm       string `mapstructure:"algorithm"`
+	Header          string `mapstructure:"header"`
+	TimestampHeader string `mapstructure:"timestampHeader"`
+	Format          string `mapstructure:"format"`
+	Encoding        string `mapstructure:"encoding"`
+	Prefix          string `mapstructure:"prefix"`
 }
 
 type SystemConfig struct {
 	Disabled bool `mapstructure:"disabled"`
 }
 
+const (
+	FileFormatJSONL    = "jsonl"
+	FileFormatText     = "text"
+	FileFormatTemplate = "template"
+)
+
+type FileConfig struct {
+	Path       string `mapstructure:"path" yaml:"path"`
+	Format     string `mapstructure:"format" yaml:"format,omitempty"`
+	Template   string `mapstructure:"template" yaml:"template,omitempty"`
+	RotateSize string `mapstructure:"rotateSize" yaml:"rotateSize,omitempty"`
+}
+
+type RunConfig struct {
+	Shell            string        `mapstructure:"shell" yaml:"shell,omitempty"`
+	Tail             int           `mapstructure:"tail" yaml:"tail,omitempty"`
+	TailStderrOnly   bool          `mapstructure:"tailStderrOnly" yaml:"tailStderrOnly,omitempty"`
+	MinDuration      time.Duration `mapstructure:"minDuration" yaml:"minDuration,omitempty"`
+	NotifyOn         string        `mapstructure:"notifyOn" yaml:"notifyOn,omitempty"`
+	SuccessExitCodes []int         `mapstructure:"successExitCodes" yaml:"successExitCodes,omitempty"`
+	NotifyIfSlower   string        `mapstructure:"notifyIfSlower" yaml:"notifyIfSlower,omitempty"`
+	DisableHistory   bool          `mapstructure:"disableHistory" yaml:"disableHistory,omitempty"`
+	ETA              bool          `mapstructure:"eta" yaml:"eta,omitempty"`
+	PTY              bool          `mapstructure:"pty" yaml:"pty,omitempty"`
+	Log              string        `mapstructure:"log" yaml:"log,omitempty"`
+	LogMaxFiles      int           `mapstructure:"logMaxFiles" yaml:"logMaxFiles,omitempty"`
+	LogMaxSize       string        `mapstructure:"logMaxSize" yaml:"logMaxSize,omitempty"`
+	LogBaseURL       string        `mapstructure:"logBaseUrl" yaml:"logBaseUrl,omitempty"`
+}
+
+// ShellConfig configures the hooks printed by n-cli shell-init.
+type ShellConfig struct {
+	Threshold time.Duration `mapstructure:"threshold" yaml:"threshold,omitempty"`
+	// Ignore replaces the default list of ignored programs.
+	Ignore   []string      `mapstructure:"ignore" yaml:"ignore,omitempty"`
+	Cooldown time.Duration `mapstructure:"cooldown" yaml:"cooldown,omitempty"`
+}
+
 const (
 	HooksKey               = "hooks"
 	HookAgentCodexKey      = "codex"
@@ -45,8 +144,13 @@ type HooksConfig struct {
 type Config struct {
 	Discord *DiscordConfig `mapstructure:"discord" yaml:"discord,omitempty"`
 	Slack   *SlackConfig   `mapstructure:"slack" yaml:"slack,omitempty"`
-	Custom  *CustomConfig   `mapstructure:"custom" yaml:"custom,omitempty"`
-	Customs []CustomConfig  `mapstructure:"customs" yaml:"customs,omitempty"`
-	System  *SystemConfig   `mapstructure:"system" yaml:"system,omitempty"`
+	Custom  *CustomConfig  `mapstructure:"custom" yaml:"custom,omitempty"`
+	Customs []CustomConfig `mapstructure:"customs" yaml:"customs,omitempty"`
+	System  *SystemConfig  `mapstructure:"system" yaml:"system,omitempty"`
+	File    *FileConfig    `mapstructure:"file" yaml:"file,omitempty"`
 	Hooks   *HooksConfig   `mapstructure:"hooks" yaml:"hooks,omitempty"`
+	Run     *RunConfig     `mapstructure:"run" yaml:"run,omitempty"`
+
+	// not "shell", since AutomaticEnv would read that key from $SHELL
+	ShellIntegration *ShellConfig `mapstructure:"shellIntegration" yaml:"shellIntegration,omitempty"`
 }
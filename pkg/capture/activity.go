@@ -0,0 +1,42 @@
+package capture
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Activity records when output was last written through it.
+type Activity struct {
+	last atomic.Int64
+}
+
+func NewActivity() *Activity {
+	a := &Activity{}
+	a.Touch()
+	return a
+}
+
+// Touch marks now as the latest activity.
+func (a *Activity) Touch() {
+	a.last.Store(time.Now().UnixNano())
+}
+
+// Last returns when output was last written.
+func (a *Activity) Last() time.Time {
+	return time.Unix(0, a.last.Load())
+}
+
+// Writer returns a writer that discards its input and records activity.
+func (a *Activity) Writer() io.Writer {
+	return activityWriter{a}
+}
+
+type activityWriter struct {
+	activity *Activity
+}
+
+func (w activityWriter) Write(p []byte) (int, error) {
+	w.activity.Touch()
+	return len(p), nil
+}
@@ -0,0 +1,21 @@
+package capture
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivity(t *testing.T) {
+	a := NewActivity()
+	started := a.Last()
+	assert.WithinDuration(t, time.Now(), started, time.Second)
+
+	time.Sleep(5 * time.Millisecond)
+	n, err := io.WriteString(a.Writer(), "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.True(t, a.Last().After(started))
+}
@@ -0,0 +1,45 @@
+package capture
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/lba-studio/n-cli/pkg/formatter"
+)
+
+// Buffer holds output in memory, up to a limit. Anything past the limit is
+// counted but dropped.
+type Buffer struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	limit   int
+	dropped int64
+}
+
+func NewBuffer(limit int) *Buffer {
+	return &Buffer{limit: limit}
+}
+
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := min(len(p), b.limit-b.buf.Len())
+	b.buf.Write(p[:n])
+	b.dropped += int64(len(p) - n)
+	return len(p), nil
+}
+
+// WriteTo writes the buffered output to w, followed by a note if some of it
+// was dropped.
+func (b *Buffer) WriteTo(w io.Writer) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n, err := w.Write(b.buf.Bytes())
+	if err != nil || b.dropped == 0 {
+		return int64(n), err
+	}
+	m, err := fmt.Fprintf(w, "\n[n-cli: %s of output dropped after the first %s]\n", formatter.PrettyPrintBytes(b.dropped), formatter.PrettyPrintBytes(int64(b.limit)))
+	return int64(n + m), err
+}
@@ -0,0 +1,31 @@
+package capture
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffer(t *testing.T) {
+	b := NewBuffer(10)
+	io.WriteString(b, "hello\n")
+	var out bytes.Buffer
+	b.WriteTo(&out)
+	assert.Equal(t, "hello\n", out.String())
+}
+
+func TestBufferLimit(t *testing.T) {
+	b := NewBuffer(10)
+	n, err := io.WriteString(b, "hello\n")
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	n, err = io.WriteString(b, "world, again\n")
+	assert.NoError(t, err)
+	assert.Equal(t, 13, n, "writes past the limit still succeed")
+
+	var out bytes.Buffer
+	b.WriteTo(&out)
+	assert.Equal(t, "hello\nworl\n[n-cli: 9 B of output dropped after the first 10 B]\n", out.String())
+}
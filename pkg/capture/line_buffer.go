@@ -0,0 +1,87 @@
+package capture
+
+import (
+	"io"
+	"sync"
+
+	"github.com/lba-studio/n-cli/pkg/formatter"
+)
+
+// maxPendingLineBytes bounds how much of a single unterminated line is kept,
+// so that output without newlines cannot grow the buffer without limit.
+const maxPendingLineBytes = 4096
+
+// LineBuffer keeps the last N lines written through its writers. Each writer
+// tracks its own partial line, so interleaved streams (stdout and stderr) do
+// not get spliced together mid-line. Terminal escape sequences are stripped.
+type LineBuffer struct {
+	mu       sync.Mutex
+	maxLines int
+	lines    []string
+	next     int
+	full     bool
+	writers  []*lineWriter
+}
+
+func NewLineBuffer(maxLines int) *LineBuffer {
+	return &LineBuffer{
+		maxLines: maxLines,
+		lines:    make([]string, maxLines),
+	}
+}
+
+// Writer returns a new stream that feeds lines into the buffer.
+func (b *LineBuffer) Writer() io.Writer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	w := &lineWriter{buf: b}
+	b.writers = append(b.writers, w)
+	return w
+}
+
+// Lines returns the buffered lines, oldest first, including any partial line
+// that has not been terminated yet.
+func (b *LineBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out []string
+	if b.full {
+		out = append(out, b.lines[b.next:]...)
+	}
+	out = append(out, b.lines[:b.next]...)
+	for _, w := range b.writers {
+		if pending := w.splitter.pending; len(pending) > 0 {
+			out = append(out, formatter.StripANSI(string(pending)))
+		}
+	}
+	if len(out) > b.maxLines {
+		out = out[len(out)-b.maxLines:]
+	}
+	return out
+}
+
+// push must be called with b.mu held.
+func (b *LineBuffer) push(line string) {
+	if b.maxLines <= 0 {
+		return
+	}
+	b.lines[b.next] = formatter.StripANSI(line)
+	b.next++
+	if b.next == b.maxLines {
+		b.next = 0
+		b.full = true
+	}
+}
+
+type lineWriter struct {
+	buf      *LineBuffer
+	splitter lineSplitter
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf.mu.Lock()
+	defer w.buf.mu.Unlock()
+	w.splitter.write(p, w.buf.push)
+	return len(p), nil
+}
@@ -0,0 +1,66 @@
+package capture
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineBuffer(t *testing.T) {
+	t.Run("keeps the last N lines", func(t *testing.T) {
+		b := NewLineBuffer(3)
+		w := b.Writer()
+		for i := 1; i <= 5; i++ {
+			fmt.Fprintf(w, "line %d\n", i)
+		}
+		assert.Equal(t, []string{"line 3", "line 4", "line 5"}, b.Lines())
+	})
+
+	t.Run("fewer lines than capacity", func(t *testing.T) {
+		b := NewLineBuffer(10)
+		io.WriteString(b.Writer(), "one\ntwo\n")
+		assert.Equal(t, []string{"one", "two"}, b.Lines())
+	})
+
+	t.Run("joins lines split across writes and keeps partial lines", func(t *testing.T) {
+		b := NewLineBuffer(10)
+		w := b.Writer()
+		io.WriteString(w, "hel")
+		io.WriteString(w, "lo\nwor")
+		io.WriteString(w, "ld")
+		assert.Equal(t, []string{"hello", "world"}, b.Lines())
+	})
+
+	t.Run("streams do not splice each other's partial lines", func(t *testing.T) {
+		b := NewLineBuffer(10)
+		stdout, stderr := b.Writer(), b.Writer()
+		io.WriteString(stdout, "compiling ")
+		io.WriteString(stderr, "warning: deprecated\n")
+		io.WriteString(stdout, "done\n")
+		assert.Equal(t, []string{"warning: deprecated", "compiling done"}, b.Lines())
+	})
+
+	t.Run("strips ANSI escape sequences", func(t *testing.T) {
+		b := NewLineBuffer(10)
+		io.WriteString(b.Writer(), "\x1b[31merror\x1b[0m: boom\n")
+		assert.Equal(t, []string{"error: boom"}, b.Lines())
+	})
+
+	t.Run("bounds very long lines", func(t *testing.T) {
+		b := NewLineBuffer(10)
+		io.WriteString(b.Writer(), strings.Repeat("x", maxPendingLineBytes*2)+"\nnext\n")
+		lines := b.Lines()
+		assert.Len(t, lines, 2)
+		assert.Equal(t, strings.Repeat("x", maxPendingLineBytes)+"…", lines[0])
+		assert.Equal(t, "next", lines[1])
+	})
+
+	t.Run("zero capacity keeps nothing", func(t *testing.T) {
+		b := NewLineBuffer(0)
+		io.WriteString(b.Writer(), "one\ntwo")
+		assert.Empty(t, b.Lines())
+	})
+}
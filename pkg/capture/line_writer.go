@@ -0,0 +1,68 @@
+package capture
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/lba-studio/n-cli/pkg/formatter"
+)
+
+// NewLineWriter returns a writer that calls onLine for every complete line
+// written to it, with terminal escape sequences stripped. Lines longer than
+// 4096 bytes are cut short.
+func NewLineWriter(onLine func(line string)) io.Writer {
+	return &callbackLineWriter{onLine: onLine}
+}
+
+type callbackLineWriter struct {
+	mu       sync.Mutex
+	splitter lineSplitter
+	onLine   func(line string)
+}
+
+func (w *callbackLineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.splitter.write(p, func(line string) {
+		w.onLine(formatter.StripANSI(line))
+	})
+	return len(p), nil
+}
+
+// lineSplitter cuts a stream into lines, keeping at most maxPendingLineBytes
+// of the line that is still being written.
+type lineSplitter struct {
+	pending   []byte
+	truncated bool
+}
+
+func (s *lineSplitter) write(p []byte, emit func(line string)) {
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		chunk := p
+		if i >= 0 {
+			chunk = p[:i]
+		}
+		if room := maxPendingLineBytes - len(s.pending); room > 0 {
+			if len(chunk) > room {
+				chunk = chunk[:room]
+				s.truncated = true
+			}
+			s.pending = append(s.pending, chunk...)
+		} else if len(chunk) > 0 {
+			s.truncated = true
+		}
+		if i < 0 {
+			return
+		}
+		line := string(s.pending)
+		if s.truncated {
+			line += "…"
+		}
+		emit(line)
+		s.pending = s.pending[:0]
+		s.truncated = false
+		p = p[i+1:]
+	}
+}
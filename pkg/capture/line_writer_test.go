@@ -0,0 +1,20 @@
+package capture
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := NewLineWriter(func(line string) {
+		lines = append(lines, line)
+	})
+	io.WriteString(w, "Compiling...\n\x1b[32mCompiled")
+	assert.Equal(t, []string{"Compiling..."}, lines)
+
+	io.WriteString(w, " successfully\x1b[0m\nwaiting")
+	assert.Equal(t, []string{"Compiling...", "Compiled successfully"}, lines)
+}
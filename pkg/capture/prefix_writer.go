@@ -0,0 +1,71 @@
+package capture
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// PrefixWriter writes whole lines to out, each starting with prefix. Writers
+// that share a mutex never interleave within a line, so several commands can
+// write to the same terminal.
+type PrefixWriter struct {
+	mu      *sync.Mutex
+	out     io.Writer
+	prefix  []byte
+	pending []byte
+}
+
+func NewPrefixWriter(out io.Writer, mu *sync.Mutex, prefix string) *PrefixWriter {
+	return &PrefixWriter{mu: mu, out: out, prefix: []byte(prefix)}
+}
+
+func (w *PrefixWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	var lines []byte
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		lines = w.appendLine(lines, w.pending[:i])
+		w.pending = w.pending[i+1:]
+	}
+	// don't hold back output without newlines forever
+	if len(w.pending) > maxPendingLineBytes {
+		lines = w.appendLine(lines, w.pending)
+		w.pending = nil
+	}
+	if len(lines) == 0 {
+		return len(p), nil
+	}
+	// the next write can reuse the buffer instead of growing it forever
+	w.pending = append([]byte(nil), w.pending...)
+	if err := w.write(lines); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the last line, if it has not ended yet.
+func (w *PrefixWriter) Flush() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	lines := w.appendLine(nil, w.pending)
+	w.pending = nil
+	return w.write(lines)
+}
+
+func (w *PrefixWriter) appendLine(lines, line []byte) []byte {
+	lines = append(lines, w.prefix...)
+	lines = append(lines, line...)
+	return append(lines, '\n')
+}
+
+func (w *PrefixWriter) write(lines []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.out.Write(lines)
+	return err
+}
@@ -0,0 +1,35 @@
+package capture
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	a := NewPrefixWriter(&out, &mu, "[1] ")
+	b := NewPrefixWriter(&out, &mu, "[2] ")
+
+	io.WriteString(a, "Compiling...\n\x1b[32mCompi")
+	io.WriteString(b, "Testing\n")
+	io.WriteString(a, "led\x1b[0m\n")
+	io.WriteString(b, "no newline")
+	assert.Equal(t, "[1] Compiling...\n[2] Testing\n[1] \x1b[32mCompiled\x1b[0m\n", out.String())
+
+	assert.NoError(t, b.Flush())
+	assert.NoError(t, a.Flush())
+	assert.Equal(t, "[1] Compiling...\n[2] Testing\n[1] \x1b[32mCompiled\x1b[0m\n[2] no newline\n", out.String())
+}
+
+func TestPrefixWriterLongLine(t *testing.T) {
+	var out bytes.Buffer
+	w := NewPrefixWriter(&out, &sync.Mutex{}, "> ")
+	io.WriteString(w, strings.Repeat("x", maxPendingLineBytes+1))
+	assert.Equal(t, "> "+strings.Repeat("x", maxPendingLineBytes+1)+"\n", out.String())
+}
@@ -0,0 +1,45 @@
+// Package cgroup runs commands in a transient cgroup v2, for exact accounting
+// of everything they start and optional resource limits.
+package cgroup
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotSupported        = errors.New("cgroups are only supported on Linux")
+	ErrNoCgroup2           = errors.New("no cgroup v2 hierarchy is mounted")
+	ErrNoDelegatedCgroup   = errors.New("cannot find a delegated cgroup (user@<uid>.service) to create the cgroup in; pass a parent cgroup explicitly")
+	ErrControllerNotActive = errors.New("controller is not available in the cgroup")
+)
+
+type Limits struct {
+	// MemoryMax is written to memory.max, in bytes. Zero means no limit.
+	MemoryMax int64
+	// CPUMax is the number of CPUs the cgroup may use, e.g. 1.5. Zero means no limit.
+	CPUMax float64
+}
+
+// Stats is the accounting of everything that ran in the cgroup. Values that
+// the kernel does not provide (e.g. memory.peak before Linux 5.19, or
+// controllers that are not delegated) are zero.
+type Stats struct {
+	CPUUser          time.Duration
+	CPUSystem        time.Duration
+	ThrottledPeriods int64
+	ThrottledTime    time.Duration
+	MemoryPeak       int64
+	MemoryMax        int64
+	ReadBytes        int64
+	WriteBytes       int64
+	OOMKills         int64
+	// Processes is how many processes are still in the cgroup, e.g. daemons
+	// that the command left behind.
+	Processes int
+}
+
+// CPUTime is the total CPU time, in user and kernel mode.
+func (s *Stats) CPUTime() time.Duration {
+	return s.CPUUser + s.CPUSystem
+}
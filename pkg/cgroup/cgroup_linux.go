@@ -0,0 +1,268 @@
+//go:build linux
+
+package cgroup
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// controllers are enabled for the cgroup when the parent has them.
+var controllers = []string{"cpu", "memory", "io"}
+
+// cpuMaxPeriod is the cpu.max period in microseconds (the kernel's default).
+const cpuMaxPeriod = 100000
+
+type Cgroup struct {
+	// Path is the cgroup's directory, e.g. /sys/fs/cgroup/user.slice/.../run-1234.
+	Path string
+	dir  *os.File
+}
+
+// DefaultParent returns the cgroup that n-cli creates its cgroups in:
+// n-cli.slice under the systemd user manager (user@<uid>.service) that n-cli
+// runs under, which systemd delegates to the user.
+func DefaultParent() (string, error) {
+	mount, err := cgroup2Mount()
+	if err != nil {
+		return "", err
+	}
+	own, err := ownCgroup()
+	if err != nil {
+		return "", err
+	}
+	service := fmt.Sprintf("user@%d.service", os.Getuid())
+	parts := strings.Split(own, "/")
+	for i, part := range parts {
+		if part == service {
+			return filepath.Join(mount, filepath.Join(parts[:i+1]...), "n-cli.slice"), nil
+		}
+	}
+	return "", ErrNoDelegatedCgroup
+}
+
+// New creates the cgroup parent/name, creating parent if needed, and applies
+// limits to it.
+func New(parent, name string, limits Limits) (*Cgroup, error) {
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return nil, err
+	}
+	enableControllers(parent)
+	path := filepath.Join(parent, name)
+	if err := os.Mkdir(path, 0o755); err != nil {
+		return nil, err
+	}
+	c := &Cgroup{Path: path}
+	if err := c.applyLimits(limits); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	dir, err := os.Open(path)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	c.dir = dir
+	return c, nil
+}
+
+// enableControllers makes the controllers available to the children of path.
+// Controllers the parent doesn't have are skipped; New reports them if a
+// limit needs them.
+func enableControllers(path string) {
+	available, err := os.ReadFile(filepath.Join(path, "cgroup.controllers"))
+	if err != nil {
+		return
+	}
+	for _, controller := range controllers {
+		if !hasField(string(available), controller) {
+			continue
+		}
+		_ = os.WriteFile(filepath.Join(path, "cgroup.subtree_control"), []byte("+"+controller), 0o644)
+	}
+}
+
+func (c *Cgroup) applyLimits(limits Limits) error {
+	if limits.MemoryMax > 0 {
+		if err := c.write("memory", "memory.max", strconv.FormatInt(limits.MemoryMax, 10)); err != nil {
+			return err
+		}
+	}
+	if limits.CPUMax > 0 {
+		quota := int64(limits.CPUMax * cpuMaxPeriod)
+		if err := c.write("cpu", "cpu.max", fmt.Sprintf("%d %d", quota, cpuMaxPeriod)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cgroup) write(controller, file, value string) error {
+	active, _ := os.ReadFile(filepath.Join(c.Path, "cgroup.controllers"))
+	if !hasField(string(active), controller) {
+		return fmt.Errorf("%w: %s (needed for %s)", ErrControllerNotActive, controller, file)
+	}
+	return os.WriteFile(filepath.Join(c.Path, file), []byte(value), 0o644)
+}
+
+// Attach makes cmd start inside the cgroup. Everything it starts stays there,
+// including daemons that detach from it.
+func (c *Cgroup) Attach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(c.dir.Fd())
+}
+
+func (c *Cgroup) Stats() (*Stats, error) {
+	cpu, err := c.readKeyed("cpu.stat")
+	if err != nil {
+		return nil, err
+	}
+	s := &Stats{
+		CPUUser:          time.Duration(cpu["user_usec"]) * time.Microsecond,
+		CPUSystem:        time.Duration(cpu["system_usec"]) * time.Microsecond,
+		ThrottledPeriods: cpu["nr_throttled"],
+		ThrottledTime:    time.Duration(cpu["throttled_usec"]) * time.Microsecond,
+	}
+	s.MemoryPeak, _ = c.readInt("memory.peak")
+	s.MemoryMax, _ = c.readInt("memory.max")
+	if events, err := c.readKeyed("memory.events"); err == nil {
+		s.OOMKills = events["oom_kill"]
+	}
+	s.ReadBytes, s.WriteBytes, _ = c.readIO()
+	if procs, err := os.ReadFile(filepath.Join(c.Path, "cgroup.procs")); err == nil {
+		s.Processes = len(strings.Fields(string(procs)))
+	}
+	return s, nil
+}
+
+// Close removes the cgroup, unless processes are still running in it.
+func (c *Cgroup) Close() error {
+	if c.dir != nil {
+		c.dir.Close()
+	}
+	if procs, err := os.ReadFile(filepath.Join(c.Path, "cgroup.procs")); err == nil && len(strings.TrimSpace(string(procs))) > 0 {
+		return nil
+	}
+	return os.Remove(c.Path)
+}
+
+func (c *Cgroup) readInt(file string) (int64, error) {
+	b, err := os.ReadFile(filepath.Join(c.Path, file))
+	if err != nil {
+		return 0, err
+	}
+	// memory.max is "max" when unlimited
+	return strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// readKeyed reads files made of "key value" lines, such as cpu.stat.
+func (c *Cgroup) readKeyed(file string) (map[string]int64, error) {
+	f, err := os.Open(filepath.Join(c.Path, file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	values := map[string]int64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			values[key] = n
+		}
+	}
+	return values, scanner.Err()
+}
+
+// readIO sums the bytes read and written over all devices in io.stat.
+func (c *Cgroup) readIO() (int64, int64, error) {
+	f, err := os.Open(filepath.Join(c.Path, "io.stat"))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	var read, write int64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// e.g. "8:0 rbytes=1459200 wbytes=314773504 rios=192 wios=353 ..."
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			n, _ := strconv.ParseInt(value, 10, 64)
+			switch key {
+			case "rbytes":
+				read += n
+			case "wbytes":
+				write += n
+			}
+		}
+	}
+	return read, write, scanner.Err()
+}
+
+// cgroup2Mount returns where the cgroup v2 hierarchy is mounted, usually
+// /sys/fs/cgroup (or /sys/fs/cgroup/unified on hybrid systems).
+func cgroup2Mount() (string, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// "36 25 0:31 / /sys/fs/cgroup rw,nosuid - cgroup2 cgroup2 rw"
+		fields := strings.Fields(scanner.Text())
+		for i, field := range fields {
+			if field == "-" && i+1 < len(fields) && fields[i+1] == "cgroup2" && len(fields) > 4 {
+				return fields[4], nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", ErrNoCgroup2
+}
+
+// ownCgroup returns n-cli's own cgroup v2 path, relative to the mount.
+func ownCgroup() (string, error) {
+	b, err := os.ReadFile("/proc/self/cgroup")
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrNoCgroup2
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return strings.TrimPrefix(path, "/"), nil
+		}
+	}
+	return "", ErrNoCgroup2
+}
+
+func hasField(s, field string) bool {
+	for _, f := range strings.Fields(s) {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+//go:build linux
+
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cpu.stat":      "usage_usec 3500000\nuser_usec 3000000\nsystem_usec 500000\nnr_periods 40\nnr_throttled 12\nthrottled_usec 250000\n",
+		"memory.peak":   "1073741824\n",
+		"memory.max":    "max\n",
+		"memory.events": "low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n",
+		"io.stat":       "8:0 rbytes=1024 wbytes=4096 rios=1 wios=2 dbytes=0 dios=0\n259:0 rbytes=1024 wbytes=0 rios=1 wios=0 dbytes=0 dios=0\n",
+		"cgroup.procs":  "1234\n1240\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	s, err := (&Cgroup{Path: dir}).Stats()
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{
+		CPUUser:          3 * time.Second,
+		CPUSystem:        500 * time.Millisecond,
+		ThrottledPeriods: 12,
+		ThrottledTime:    250 * time.Millisecond,
+		MemoryPeak:       1073741824,
+		ReadBytes:        2048,
+		WriteBytes:       4096,
+		OOMKills:         1,
+		Processes:        2,
+	}, s)
+	assert.Equal(t, 3500*time.Millisecond, s.CPUTime())
+}
+
+func TestStatsWithoutControllers(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("usage_usec 10\nuser_usec 6\nsystem_usec 4\n"), 0o644))
+
+	s, err := (&Cgroup{Path: dir}).Stats()
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{CPUUser: 6 * time.Microsecond, CPUSystem: 4 * time.Microsecond}, s)
+}
+
+func TestNewWithoutController(t *testing.T) {
+	parent := t.TempDir()
+	_, err := New(parent, "run-1.scope", Limits{MemoryMax: 1 << 30})
+	assert.ErrorIs(t, err, ErrControllerNotActive)
+	assert.NoDirExists(t, filepath.Join(parent, "run-1.scope"))
+}
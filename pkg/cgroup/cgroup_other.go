@@ -0,0 +1,29 @@
+//go:build !linux
+
+package cgroup
+
+import "os/exec"
+
+type Cgroup struct {
+	Path string
+}
+
+func DefaultParent() (string, error) {
+	// not supported (for now?)
+	return "", ErrNotSupported
+}
+
+func New(parent, name string, limits Limits) (*Cgroup, error) {
+	// not supported (for now?)
+	return nil, ErrNotSupported
+}
+
+func (c *Cgroup) Attach(cmd *exec.Cmd) {}
+
+func (c *Cgroup) Stats() (*Stats, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *Cgroup) Close() error {
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package cronjob tracks scheduled runs between invocations of n-cli cron.
+package cronjob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/lockfile"
+)
+
+// State is kept between runs of a job.
+type State struct {
+	// Failures is how many runs in a row have failed.
+	Failures     int       `json:"failures"`
+	FailingSince time.Time `json:"failingSince,omitzero"`
+}
+
+// StateName is the name of the job's state in the state store. Jobs are
+// named explicitly, or after their command line and working directory.
+func StateName(name, command, cwd string) string {
+	if name != "" {
+		return "cron-" + name
+	}
+	sum := sha256.Sum256([]byte(cwd + "\x00" + command))
+	return "cron-" + hex.EncodeToString(sum[:8])
+}
+
+// Record updates the state with the outcome of a run started at startedAt.
+// When a run succeeds after failures, it returns how many runs had failed.
+func (s *State) Record(succeeded bool, startedAt time.Time) (recoveredAfter int) {
+	if succeeded {
+		recoveredAfter = s.Failures
+		*s = State{}
+		return recoveredAfter
+	}
+	if s.Failures == 0 {
+		s.FailingSince = startedAt
+	}
+	s.Failures++
+	return 0
+}
+
+// FormatRecovered is the notification for a job that succeeded again.
+func FormatRecovered(command string, failures int, failingSince time.Time, elapsed time.Duration) string {
+	runs := "failure"
+	if failures > 1 {
+		runs = "failures in a row"
+	}
+	lines := []string{fmt.Sprintf("Command `%s` succeeded again after %d %s.", command, failures, runs)}
+	if !failingSince.IsZero() {
+		lines = append(lines, fmt.Sprintf("Failing since: %s", failingSince.Format("2006-01-02 15:04")))
+	}
+	lines = append(lines, fmt.Sprintf("Elapsed: %s", roundDuration(elapsed)))
+	return strings.Join(lines, "\n")
+}
+
+// FormatSkipped is the notification for a run that did not start because the
+// previous one still holds the lock. holder is nil if it could not be read.
+func FormatSkipped(command, lockPath string, holder *lockfile.Holder, now time.Time) string {
+	lines := []string{fmt.Sprintf("Command `%s` was SKIPPED: the previous run still holds the lock %s.", command, lockPath)}
+	if holder != nil {
+		lines = append(lines, fmt.Sprintf("Previous run: pid %d, running since %s (%s)", holder.Pid, holder.Since.Format("2006-01-02 15:04"), now.Sub(holder.Since).Round(time.Second)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// roundDuration keeps a few significant digits, so that short runs do not show
+// up as 0s.
+func roundDuration(d time.Duration) time.Duration {
+	if d >= 10*time.Second {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Millisecond)
+}
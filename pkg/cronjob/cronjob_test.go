@@ -0,0 +1,48 @@
+package cronjob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/lockfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateName(t *testing.T) {
+	assert.Equal(t, "cron-backup", StateName("backup", "backup.sh", "/srv"))
+	a := StateName("", "backup.sh", "/srv")
+	assert.Regexp(t, `^cron-[0-9a-f]{16}$`, a)
+	assert.Equal(t, a, StateName("", "backup.sh", "/srv"))
+	assert.NotEqual(t, a, StateName("", "backup.sh", "/home"))
+	assert.NotEqual(t, a, StateName("", "backup.sh --full", "/srv"))
+}
+
+func TestRecord(t *testing.T) {
+	first := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	s := State{}
+	assert.Equal(t, 0, s.Record(true, first))
+
+	assert.Equal(t, 0, s.Record(false, first))
+	assert.Equal(t, 0, s.Record(false, first.Add(time.Hour)))
+	assert.Equal(t, State{Failures: 2, FailingSince: first}, s)
+
+	assert.Equal(t, 2, s.Record(true, first.Add(2*time.Hour)))
+	assert.Equal(t, State{}, s)
+	assert.Equal(t, 0, s.Record(true, first.Add(3*time.Hour)))
+}
+
+func TestFormatRecovered(t *testing.T) {
+	since := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, "Command `backup.sh` succeeded again after 3 failures in a row.\nFailing since: 2026-10-19 03:00\nElapsed: 12s",
+		FormatRecovered("backup.sh", 3, since, 12300*time.Millisecond))
+	assert.Equal(t, "Command `backup.sh` succeeded again after 1 failure.\nElapsed: 1.235s",
+		FormatRecovered("backup.sh", 1, time.Time{}, 1234567*time.Microsecond))
+}
+
+func TestFormatSkipped(t *testing.T) {
+	since := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, "Command `backup.sh` was SKIPPED: the previous run still holds the lock /tmp/backup.lock.\nPrevious run: pid 42, running since 2026-10-19 03:00 (1h30m0s)",
+		FormatSkipped("backup.sh", "/tmp/backup.lock", &lockfile.Holder{Pid: 42, Since: since}, since.Add(90*time.Minute)))
+	assert.Equal(t, "Command `backup.sh` was SKIPPED: the previous run still holds the lock /tmp/backup.lock.",
+		FormatSkipped("backup.sh", "/tmp/backup.lock", nil, since))
+}
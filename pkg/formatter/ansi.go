@@ -0,0 +1,31 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ansiPattern matches CSI sequences (colours, cursor movement), OSC sequences
+// (window titles, hyperlinks) and the remaining two-byte escapes.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// StripANSI removes terminal escape sequences from a single line of output.
+// Carriage returns used by progress bars are resolved to the text that would
+// be visible last.
+func StripANSI(line string) string {
+	line = ansiPattern.ReplaceAllString(line, "")
+	line = strings.TrimRight(line, "\r\n")
+	if i := strings.LastIndex(line, "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	return line
+}
+
+// TruncateLine shortens line to at most limit runes, marking the cut with "…".
+func TruncateLine(line string, limit int) string {
+	runes := []rune(line)
+	if limit <= 0 || len(runes) <= limit {
+		return line
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,49 @@
+package formatter
+
+import (
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "plain", input: "hello", expected: "hello"},
+		{name: "colours", input: "\x1b[1;31mFAIL\x1b[0m: test", expected: "FAIL: test"},
+		{name: "osc hyperlink", input: "\x1b]8;;https://example.com\x07link\x1b]8;;\x07", expected: "link"},
+		{name: "progress bar", input: "10%\r50%\r100% done\r\n", expected: "100% done"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := StripANSI(tc.input)
+			if result != tc.expected {
+				t.Errorf("Expected: %q, but got: %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestTruncateLine(t *testing.T) {
+	testCases := []struct {
+		input    string
+		limit    int
+		expected string
+	}{
+		{input: "short", limit: 10, expected: "short"},
+		{input: "exactly10!", limit: 10, expected: "exactly10!"},
+		{input: "this is too long", limit: 8, expected: "this is…"},
+		{input: "ünïcødé ünïcødé", limit: 5, expected: "ünïc…"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			result := TruncateLine(tc.input, tc.limit)
+			if result != tc.expected {
+				t.Errorf("Expected: %q, but got: %q", tc.expected, result)
+			}
+		})
+	}
+}
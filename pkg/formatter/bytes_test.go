@@ -0,0 +1,63 @@
+package formatter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{input: "512", expected: 512},
+		{input: "64KB", expected: 64 * 1024},
+		{input: "10M", expected: 10 * 1024 * 1024},
+		{input: "1.5GiB", expected: 3 * 512 * 1024 * 1024},
+		{input: " 2 gb ", expected: 2 * 1024 * 1024 * 1024},
+		{input: "", wantErr: true},
+		{input: "MB", wantErr: true},
+		{input: "12 parsecs", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := ParseByteSize(tc.input)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidByteSize) {
+					t.Errorf("Expected ErrInvalidByteSize, but got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if result != tc.expected {
+				t.Errorf("Expected: %d, but got: %d", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestPrettyPrintBytes(t *testing.T) {
+	testCases := []struct {
+		input    int64
+		expected string
+	}{
+		{input: 0, expected: "0 B"},
+		{input: 1023, expected: "1023 B"},
+		{input: 1024, expected: "1.0 KiB"},
+		{input: 123456789, expected: "117.7 MiB"},
+		{input: 3 * 512 * 1024 * 1024, expected: "1.5 GiB"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			result := PrettyPrintBytes(tc.input)
+			if result != tc.expected {
+				t.Errorf("Expected: %s, but got: %s", tc.expected, result)
+			}
+		})
+	}
+}
@@ -1,9 +1,25 @@
 package formatter
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 )
 
+var ErrInvalidFactor = errors.New("invalid factor (expected e.g. 1.5x)")
+
+// ParseFactor parses multipliers such as "1.5x", "2X" or "1.5". Factors must be
+// greater than zero.
+func ParseFactor(s string) (float64, error) {
+	num := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "x")
+	value, err := strconv.ParseFloat(num, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidFactor, s)
+	}
+	return value, nil
+}
+
 func PrettyPrintInt64(num int64) string {
 	isNegative := false
 	if num < 0 {
@@ -1,6 +1,7 @@
 package formatter
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -28,3 +29,34 @@ func TestPrettyPrintInt64(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFactor(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected float64
+		wantErr  bool
+	}{
+		{input: "1.5x", expected: 1.5},
+		{input: "2X", expected: 2},
+		{input: " 1.25 ", expected: 1.25},
+		{input: "x", wantErr: true},
+		{input: "0x", wantErr: true},
+		{input: "-2x", wantErr: true},
+		{input: "fast", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := ParseFactor(tc.input)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidFactor) {
+					t.Errorf("Expected ErrInvalidFactor, but got: %v", err)
+				}
+				return
+			}
+			if err != nil || result != tc.expected {
+				t.Errorf("Expected: %v, but got: %v (err: %v)", tc.expected, result, err)
+			}
+		})
+	}
+}
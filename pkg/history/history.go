@@ -0,0 +1,123 @@
+// Package history records every n-cli run, so that a run can be compared to
+// the usual duration of the same command.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxEntries is how many runs the history keeps. Once it holds 10% more, it
+// is trimmed back to MaxEntries, so that it isn't rewritten on every run.
+const MaxEntries = 5000
+
+// NeedsTrim reports whether a history with n entries should be trimmed.
+func NeedsTrim(n int) bool {
+	return n > MaxEntries+MaxEntries/10
+}
+
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Command   string    `json:"command"`
+	Cwd       string    `json:"cwd"`
+	ExitCode  int       `json:"exitCode"`
+	Succeeded bool      `json:"succeeded"`
+	// Elapsed, CPUTime and MaxRSS are in nanoseconds and bytes.
+	Elapsed time.Duration `json:"elapsed"`
+	CPUTime time.Duration `json:"cpuTime,omitempty"`
+	MaxRSS  int64         `json:"maxRss,omitempty"`
+}
+
+// Store is a JSON Lines file with one Entry per line.
+type Store struct {
+	Path string
+}
+
+// DefaultPath is ~/.n-cli/history.jsonl.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".n-cli", "history.jsonl"), nil
+}
+
+func NewStore(path string) *Store {
+	return &Store{Path: path}
+}
+
+// Load returns every entry, oldest first. A missing file is an empty history,
+// and lines that cannot be parsed are skipped.
+func (s *Store) Load() ([]Entry, error) {
+	f, err := os.Open(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+func (s *Store) Append(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Trim rewrites the history with only the newest keep entries.
+func (s *Store) Trim(keep int) error {
+	entries, err := s.Load()
+	if err != nil || len(entries) <= keep {
+		return err
+	}
+	var b strings.Builder
+	for _, e := range entries[len(entries)-keep:] {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	// replace the file atomically, so that a crash cannot lose the history
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// NormalizeCommand collapses whitespace, so that `make  build` and
+// `make build` share a history.
+func NormalizeCommand(command string) string {
+	return strings.Join(strings.Fields(command), " ")
+}
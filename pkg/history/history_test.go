@@ -0,0 +1,56 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "n-cli", "history.jsonl"))
+
+	entries, err := s.Load()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := range 5 {
+		require.NoError(t, s.Append(Entry{Time: now, Command: "make build", Cwd: "/src", Succeeded: true, Elapsed: time.Duration(i+1) * time.Minute}))
+	}
+	entries, err = s.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+	assert.Equal(t, Entry{Time: now, Command: "make build", Cwd: "/src", Succeeded: true, Elapsed: time.Minute}, entries[0])
+
+	require.NoError(t, s.Trim(2))
+	entries, err = s.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, 4*time.Minute, entries[0].Elapsed)
+	assert.Equal(t, 5*time.Minute, entries[1].Elapsed)
+}
+
+func TestLoadSkipsBadLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"command\":\"a\"}\nnot json\n{\"command\":\"b\"}\n"), 0o600))
+
+	entries, err := NewStore(path).Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "b", entries[1].Command)
+}
+
+func TestNormalizeCommand(t *testing.T) {
+	assert.Equal(t, "make build", NormalizeCommand("  make \t build "))
+	assert.Equal(t, "go test ./...", NormalizeCommand("go test ./..."))
+}
+
+func TestNeedsTrim(t *testing.T) {
+	assert.False(t, NeedsTrim(MaxEntries))
+	assert.False(t, NeedsTrim(MaxEntries+MaxEntries/10))
+	assert.True(t, NeedsTrim(MaxEntries+MaxEntries/10+1))
+}
@@ -0,0 +1,121 @@
+package history
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// MinRuns is how many successful runs of a command are needed before a run
+// can be compared to them.
+const MinRuns = 3
+
+// baselineWindow limits the baseline to recent runs, so that it follows
+// commands that got faster or slower for good.
+const baselineWindow = 20
+
+// Baseline is the usual duration of a command.
+type Baseline struct {
+	P50  time.Duration
+	Runs int
+}
+
+// Ratio is how many times longer elapsed is than usual.
+func (b *Baseline) Ratio(elapsed time.Duration) float64 {
+	if b.P50 <= 0 {
+		return 0
+	}
+	return float64(elapsed) / float64(b.P50)
+}
+
+// BaselineFor returns the usual duration of the successful runs of command in
+// cwd, or nil if there are fewer than MinRuns of them.
+func BaselineFor(entries []Entry, command, cwd string) *Baseline {
+	var durations []time.Duration
+	for _, e := range entries {
+		if e.Succeeded && e.Command == command && e.Cwd == cwd {
+			durations = append(durations, e.Elapsed)
+		}
+	}
+	return baseline(durations)
+}
+
+func baseline(durations []time.Duration) *Baseline {
+	if len(durations) < MinRuns {
+		return nil
+	}
+	if len(durations) > baselineWindow {
+		durations = durations[len(durations)-baselineWindow:]
+	}
+	return &Baseline{P50: Percentile(durations, 50), Runs: len(durations)}
+}
+
+// Percentile returns the p-th percentile (0-100) of durations, using the
+// nearest-rank method.
+func Percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// CommandStats summarises the runs of one command in one directory.
+type CommandStats struct {
+	Command  string
+	Cwd      string
+	Runs     int
+	Failures int
+	// P50 and P90 are over successful runs only.
+	P50     time.Duration
+	P90     time.Duration
+	Last    time.Duration
+	LastRun time.Time
+	// Change is how the last successful run compares to the baseline of the
+	// runs before it, e.g. 2.1 for 2.1× slower. It is 0 without enough history.
+	Change float64
+}
+
+// Summarize groups entries by command and directory, in order of first use.
+func Summarize(entries []Entry) []CommandStats {
+	type key struct{ command, cwd string }
+	var order []key
+	groups := map[key][]Entry{}
+	for _, e := range entries {
+		k := key{e.Command, e.Cwd}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], e)
+	}
+	stats := make([]CommandStats, 0, len(order))
+	for _, k := range order {
+		stats = append(stats, summarize(k.command, k.cwd, groups[k]))
+	}
+	return stats
+}
+
+func summarize(command, cwd string, entries []Entry) CommandStats {
+	s := CommandStats{Command: command, Cwd: cwd, Runs: len(entries)}
+	var durations []time.Duration
+	for _, e := range entries {
+		if e.Succeeded {
+			durations = append(durations, e.Elapsed)
+		} else {
+			s.Failures++
+		}
+	}
+	last := entries[len(entries)-1]
+	s.LastRun = last.Time
+	s.Last = last.Elapsed
+	if len(durations) > 0 {
+		s.P50 = Percentile(durations, 50)
+		s.P90 = Percentile(durations, 90)
+		if b := baseline(durations[:len(durations)-1]); b != nil {
+			s.Change = b.Ratio(durations[len(durations)-1])
+		}
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	durations := []time.Duration{5, 1, 4, 2, 3}
+	assert.Equal(t, time.Duration(3), Percentile(durations, 50))
+	assert.Equal(t, time.Duration(5), Percentile(durations, 90))
+	assert.Equal(t, time.Duration(1), Percentile(durations, 0))
+	assert.Equal(t, time.Duration(0), Percentile(nil, 50))
+	// the input is left alone
+	assert.Equal(t, []time.Duration{5, 1, 4, 2, 3}, durations)
+}
+
+func TestBaselineFor(t *testing.T) {
+	run := func(command string, succeeded bool, elapsed time.Duration) Entry {
+		return Entry{Command: command, Cwd: "/src", Succeeded: succeeded, Elapsed: elapsed}
+	}
+	entries := []Entry{
+		run("make build", true, 4*time.Minute),
+		run("make build", false, 10*time.Second),
+		run("make test", true, time.Hour),
+		run("make build", true, 5*time.Minute),
+	}
+	assert.Nil(t, BaselineFor(entries, "make build", "/src"))
+
+	entries = append(entries, run("make build", true, 3*time.Minute))
+	b := BaselineFor(entries, "make build", "/src")
+	assert.Equal(t, &Baseline{P50: 4 * time.Minute, Runs: 3}, b)
+	assert.InDelta(t, 2.25, b.Ratio(9*time.Minute), 0.001)
+	assert.Nil(t, BaselineFor(entries, "make build", "/other"))
+
+	// only recent runs count
+	for range baselineWindow {
+		entries = append(entries, run("make build", true, time.Minute))
+	}
+	assert.Equal(t, &Baseline{P50: time.Minute, Runs: baselineWindow}, BaselineFor(entries, "make build", "/src"))
+}
+
+func TestSummarize(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []Entry{
+		{Command: "make build", Cwd: "/src", Succeeded: true, Elapsed: 4 * time.Minute, Time: at},
+		{Command: "make test", Cwd: "/src", Succeeded: false, Elapsed: 10 * time.Second, Time: at},
+		{Command: "make build", Cwd: "/src", Succeeded: true, Elapsed: 4 * time.Minute, Time: at},
+		{Command: "make build", Cwd: "/src", Succeeded: false, Elapsed: time.Second, Time: at},
+		{Command: "make build", Cwd: "/src", Succeeded: true, Elapsed: 5 * time.Minute, Time: at},
+		{Command: "make build", Cwd: "/src", Succeeded: true, Elapsed: 9 * time.Minute, Time: at.Add(time.Hour)},
+	}
+	stats := Summarize(entries)
+	assert.Equal(t, []CommandStats{
+		{
+			Command: "make build", Cwd: "/src", Runs: 5, Failures: 1,
+			P50: 4 * time.Minute, P90: 9 * time.Minute, Last: 9 * time.Minute, LastRun: at.Add(time.Hour),
+			Change: 2.25,
+		},
+		{
+			Command: "make test", Cwd: "/src", Runs: 1, Failures: 1,
+			Last: 10 * time.Second, LastRun: at,
+		},
+	}, stats)
+}
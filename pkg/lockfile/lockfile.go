@@ -0,0 +1,71 @@
+// Package lockfile keeps several instances of a job from running at once.
+package lockfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrLocked = errors.New("the lock is held by another process")
+
+// Lock is an exclusive lock on a file. The operating system releases it when
+// the process exits, so a crashed run never leaves a stale lock behind.
+type Lock struct {
+	f *os.File
+}
+
+// Holder describes the process that holds a lock.
+type Holder struct {
+	Pid   int
+	Since time.Time
+}
+
+// Acquire locks path without waiting, creating the file if needed. It returns
+// ErrLocked if another process holds the lock.
+func Acquire(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	// tell whoever finds the lock held who holds it
+	if err := f.Truncate(0); err == nil {
+		fmt.Fprintf(f, "%d\n%s\n", os.Getpid(), time.Now().Format(time.RFC3339))
+	}
+	return &Lock{f: f}, nil
+}
+
+// Release unlocks the file. The file itself is left in place: removing it
+// would let another process lock a file that is about to disappear.
+func (l *Lock) Release() error {
+	_ = l.f.Truncate(0)
+	return l.f.Close()
+}
+
+// ReadHolder returns the process that locked path, as written by Acquire.
+func ReadHolder(path string) (Holder, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Holder{}, err
+	}
+	lines := strings.Fields(string(b))
+	if len(lines) < 2 {
+		return Holder{}, fmt.Errorf("unexpected lock file contents in %s", path)
+	}
+	pid, err := strconv.Atoi(lines[0])
+	if err != nil {
+		return Holder{}, err
+	}
+	since, err := time.Parse(time.RFC3339, lines[1])
+	if err != nil {
+		return Holder{}, err
+	}
+	return Holder{Pid: pid, Since: since}, nil
+}
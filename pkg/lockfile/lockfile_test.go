@@ -0,0 +1,31 @@
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.lock")
+
+	l, err := Acquire(path)
+	require.NoError(t, err)
+	holder, err := ReadHolder(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), holder.Pid)
+	assert.WithinDuration(t, time.Now(), holder.Since, 2*time.Second)
+
+	// locks are per open file, so a second open in the same process conflicts too
+	_, err = Acquire(path)
+	assert.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, l.Release())
+	l, err = Acquire(path)
+	require.NoError(t, err)
+	require.NoError(t, l.Release())
+}
@@ -0,0 +1,21 @@
+package monitor
+
+import "time"
+
+// Process is a snapshot of a process that n-cli did not start itself.
+type Process struct {
+	Pid  int
+	Name string
+	// State is the single-letter state from /proc, e.g. R, S or Z (zombie).
+	State     string
+	Cmdline   string
+	StartedAt time.Time
+	CPUTime   time.Duration
+	// PeakRSS is the highest resident set size seen so far, in bytes. Zero when unknown.
+	PeakRSS int64
+}
+
+// Exited reports whether the process is gone, or only a zombie waiting to be reaped.
+func (p *Process) Exited() bool {
+	return p.State == "Z" || p.State == "X"
+}
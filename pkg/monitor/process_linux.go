@@ -0,0 +1,147 @@
+//go:build linux
+
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetRSS returns the current resident set size of a running process in bytes.
+func GetRSS(pid int) (int64, error) {
+	// kernel threads and zombies have no VmRSS line
+	return getStatusKB(pid, "VmRSS:")
+}
+
+// clockTicks is USER_HZ, the unit of the times in /proc/<pid>/stat. It is 100
+// on every architecture Linux supports these days.
+const clockTicks = 100
+
+// GetProcess reads what /proc knows about a running process.
+func GetProcess(pid int) (*Process, error) {
+	name, fields, err := readStat(pid)
+	if err != nil {
+		return nil, err
+	}
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+	startTicks, _ := strconv.ParseInt(fields[19], 10, 64)
+	bootTime, err := getBootTime()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Process{
+		Pid:       pid,
+		Name:      name,
+		State:     fields[0],
+		StartedAt: bootTime.Add(ticksToDuration(startTicks)),
+		CPUTime:   ticksToDuration(utime + stime),
+	}
+	if cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid)); err == nil {
+		p.Cmdline = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	}
+	p.PeakRSS, _ = getStatusKB(pid, "VmHWM:")
+	return p, nil
+}
+
+// readStat returns the name of a process and the fields of /proc/<pid>/stat
+// that follow it, so that fields[0] is field 3 (state) in proc(5).
+func readStat(pid int) (string, []string, error) {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return "", nil, err
+	}
+	// the name is in parentheses and may itself contain spaces and parentheses
+	lparen, rparen := bytes.IndexByte(stat, '('), bytes.LastIndexByte(stat, ')')
+	if lparen < 0 || rparen < lparen {
+		return "", nil, fmt.Errorf("cannot parse /proc/%d/stat", pid)
+	}
+	fields := strings.Fields(string(stat[rparen+1:]))
+	if len(fields) < 22 {
+		return "", nil, fmt.Errorf("cannot parse /proc/%d/stat", pid)
+	}
+	return string(stat[lparen+1 : rparen]), fields, nil
+}
+
+// FindProcesses returns the pids of processes whose name or executable is
+// called name, leaving out n-cli itself.
+func FindProcesses(name string) ([]int, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		p, err := GetProcess(pid)
+		if err != nil {
+			// it exited while we were looking
+			continue
+		}
+		if p.Name == name || (p.Cmdline != "" && filepath.Base(strings.Fields(p.Cmdline)[0]) == name) {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+func getBootTime() (time.Time, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if btime, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			secs, err := strconv.ParseInt(strings.TrimSpace(btime), 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(secs, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("btime not found in /proc/stat")
+}
+
+func getStatusKB(pid int, key string) (int64, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), key)
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			break
+		}
+		kb, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return kb * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, ErrCallNotSupported
+}
+
+func ticksToDuration(ticks int64) time.Duration {
+	return time.Duration(ticks) * time.Second / clockTicks
+}
@@ -0,0 +1,50 @@
+//go:build linux
+
+package monitor
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRSS(t *testing.T) {
+	rss, err := GetRSS(os.Getpid())
+	require.NoError(t, err)
+	assert.Greater(t, rss, int64(1024*1024))
+
+	_, err = GetRSS(-1)
+	assert.Error(t, err)
+}
+
+func TestGetProcess(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	p, err := GetProcess(cmd.Process.Pid)
+	require.NoError(t, err)
+	assert.Equal(t, "sleep", p.Name)
+	assert.Equal(t, "sleep 30", p.Cmdline)
+	assert.False(t, p.Exited())
+	assert.WithinDuration(t, time.Now(), p.StartedAt, 5*time.Second)
+	assert.Greater(t, p.PeakRSS, int64(0))
+
+	pids, err := FindProcesses("sleep")
+	require.NoError(t, err)
+	assert.Contains(t, pids, cmd.Process.Pid)
+
+	// a zombie still has a /proc entry until it is reaped
+	require.NoError(t, cmd.Process.Kill())
+	require.Eventually(t, func() bool {
+		p, err := GetProcess(cmd.Process.Pid)
+		return err == nil && p.Exited()
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,38 @@
+//go:build !linux && !windows
+
+package monitor
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GetRSS returns the current resident set size of a running process in bytes.
+func GetRSS(pid int) (int64, error) {
+	// there's no /proc here - ps reports rss in kilobytes on macOS and the BSDs
+	out, err := exec.Command("ps", "-o", "rss=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0, err
+	}
+	kb, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return kb * 1024, nil
+}
+
+func GetProcess(pid int) (*Process, error) {
+	// not supported (for now?)
+	return nil, ErrCallNotSupported
+}
+
+func FindProcesses(name string) ([]int, error) {
+	// not supported (for now?)
+	return nil, ErrCallNotSupported
+}
+
+func readTree(root int) (map[int]treeProcess, error) {
+	// not supported (for now?)
+	return nil, ErrCallNotSupported
+}
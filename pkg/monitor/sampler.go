@@ -0,0 +1,180 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+// Sample is a snapshot of a whole process tree.
+type Sample struct {
+	Time      time.Time `json:"time"`
+	Processes int       `json:"processes"`
+	// CPUPercent is the CPU used since the previous sample; 100 is one full core.
+	CPUPercent float64 `json:"cpuPercent"`
+	RSS        int64   `json:"rssBytes"`
+	Threads    int     `json:"threads"`
+	// ReadBytes and WriteBytes are the storage I/O of the tree so far.
+	ReadBytes  int64 `json:"readBytes"`
+	WriteBytes int64 `json:"writeBytes"`
+}
+
+// SampleSummary condenses a time series of samples.
+type SampleSummary struct {
+	Samples     int     `json:"samples"`
+	AvgCPU      float64 `json:"avgCpuPercent"`
+	PeakCPU     float64 `json:"peakCpuPercent"`
+	PeakRSS     int64   `json:"peakRssBytes"`
+	PeakThreads int     `json:"peakThreads"`
+	ReadBytes   int64   `json:"readBytes"`
+	WriteBytes  int64   `json:"writeBytes"`
+}
+
+// treeProcess is what is read about one process of the tree.
+type treeProcess struct {
+	CPUTime    time.Duration
+	RSS        int64
+	Threads    int
+	ReadBytes  int64
+	WriteBytes int64
+}
+
+// Sampler polls a process and all of its descendants at a fixed interval.
+// It is only supported on Linux.
+type Sampler struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	root    int
+	samples []Sample
+	stop    chan struct{}
+	done    chan struct{}
+
+	// total CPU time as of the previous sample
+	lastCPU time.Duration
+	lastAt  time.Time
+	// latest counters per pid, so that processes that exit between samples still count
+	cpu   map[int]time.Duration
+	read  map[int]int64
+	write map[int]int64
+}
+
+func NewSampler(interval time.Duration) *Sampler {
+	return &Sampler{
+		interval: interval,
+		cpu:      map[int]time.Duration{},
+		read:     map[int]int64{},
+		write:    map[int]int64{},
+	}
+}
+
+// Start samples the tree rooted at pid. Calling it again (e.g. for a retry)
+// switches to the new tree and continues the same time series.
+func (s *Sampler) Start(pid int) error {
+	if _, err := readTree(pid); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.root = pid
+	s.lastAt = time.Now()
+	if s.stop != nil {
+		return nil
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run(s.stop, s.done)
+	return nil
+}
+
+// Stop ends sampling and waits for the current sample to finish.
+func (s *Sampler) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop = nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Samples returns the samples taken so far.
+func (s *Sampler) Samples() []Sample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Sample(nil), s.samples...)
+}
+
+func (s *Sampler) run(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.sample()
+		}
+	}
+}
+
+func (s *Sampler) sample() {
+	s.mu.Lock()
+	root := s.root
+	s.mu.Unlock()
+	tree, err := readTree(root)
+	if err != nil {
+		// the tree is gone; the command is about to be reaped
+		return
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sample := Sample{Time: now, Processes: len(tree)}
+	for pid, p := range tree {
+		sample.RSS += p.RSS
+		sample.Threads += p.Threads
+		s.cpu[pid] = p.CPUTime
+		s.read[pid] = p.ReadBytes
+		s.write[pid] = p.WriteBytes
+	}
+	var cpu time.Duration
+	for _, c := range s.cpu {
+		cpu += c
+	}
+	for _, b := range s.read {
+		sample.ReadBytes += b
+	}
+	for _, b := range s.write {
+		sample.WriteBytes += b
+	}
+	if wall := now.Sub(s.lastAt); wall > 0 {
+		sample.CPUPercent = float64(cpu-s.lastCPU) / float64(wall) * 100
+	}
+	s.lastCPU = cpu
+	s.lastAt = now
+	s.samples = append(s.samples, sample)
+}
+
+// Summarize returns averages, peaks and totals over samples.
+func Summarize(samples []Sample) SampleSummary {
+	summary := SampleSummary{Samples: len(samples)}
+	if len(samples) == 0 {
+		return summary
+	}
+	var totalCPU float64
+	for _, sample := range samples {
+		totalCPU += sample.CPUPercent
+		summary.PeakCPU = max(summary.PeakCPU, sample.CPUPercent)
+		summary.PeakRSS = max(summary.PeakRSS, sample.RSS)
+		summary.PeakThreads = max(summary.PeakThreads, sample.Threads)
+	}
+	last := samples[len(samples)-1]
+	summary.AvgCPU = totalCPU / float64(len(samples))
+	summary.ReadBytes = last.ReadBytes
+	summary.WriteBytes = last.WriteBytes
+	return summary
+}
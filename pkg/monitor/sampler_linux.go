@@ -0,0 +1,89 @@
+//go:build linux
+
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readTree reads the processes in the tree rooted at root from /proc. Finding
+// the tree takes the stat of every process, but the rest is only read for the
+// processes in it.
+func readTree(root int) (map[int]treeProcess, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	stats := map[int][]string{}
+	children := map[int][]int{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		_, fields, err := readStat(pid)
+		if err != nil {
+			// it exited while we were looking
+			continue
+		}
+		stats[pid] = fields
+		ppid, _ := strconv.Atoi(fields[1])
+		children[ppid] = append(children[ppid], pid)
+	}
+	if _, ok := stats[root]; !ok {
+		return nil, fmt.Errorf("pid %d is not running", root)
+	}
+
+	tree := map[int]treeProcess{}
+	queue := []int{root}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		tree[pid] = readTreeProcess(pid, stats[pid])
+		queue = append(queue, children[pid]...)
+	}
+	return tree, nil
+}
+
+// readTreeProcess fills in a process of the tree from the fields of its stat.
+func readTreeProcess(pid int, fields []string) treeProcess {
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+	threads, _ := strconv.Atoi(fields[17])
+	rssPages, _ := strconv.ParseInt(fields[21], 10, 64)
+	p := treeProcess{
+		CPUTime: ticksToDuration(utime + stime),
+		RSS:     rssPages * int64(os.Getpagesize()),
+		Threads: threads,
+	}
+	// /proc/<pid>/io is only readable for our own processes, which is all we need
+	p.ReadBytes, p.WriteBytes, _ = readIO(pid)
+	return p
+}
+
+func readIO(pid int) (int64, int64, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/io", pid))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	var read, write int64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "read_bytes":
+			read, _ = strconv.ParseInt(value, 10, 64)
+		case "write_bytes":
+			write, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return read, write, scanner.Err()
+}
@@ -0,0 +1,68 @@
+//go:build linux
+
+package monitor
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampler(t *testing.T) {
+	// a busy parent with an idle child, so that the tree has two processes
+	cmd := exec.Command("sh", "-c", "sleep 5 & while :; do :; done")
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		exec.Command("pkill", "-P", strconv.Itoa(cmd.Process.Pid)).Run()
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	s := NewSampler(20 * time.Millisecond)
+	require.NoError(t, s.Start(cmd.Process.Pid))
+	require.Eventually(t, func() bool {
+		samples := s.Samples()
+		return len(samples) >= 3 && samples[len(samples)-1].Processes == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	s.Stop()
+
+	samples := s.Samples()
+	last := samples[len(samples)-1]
+	assert.Greater(t, last.RSS, int64(0))
+	assert.GreaterOrEqual(t, last.Threads, 2)
+	assert.Greater(t, Summarize(samples).PeakCPU, 10.0)
+
+	// nothing is sampled after Stop
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, s.Samples(), len(samples))
+
+	assert.Error(t, NewSampler(time.Second).Start(-1))
+}
+
+func TestReadTree(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "sleep 5 & wait")
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		exec.Command("pkill", "-P", strconv.Itoa(cmd.Process.Pid)).Run()
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	var tree map[int]treeProcess
+	require.Eventually(t, func() bool {
+		var err error
+		tree, err = readTree(cmd.Process.Pid)
+		return err == nil && len(tree) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, tree, cmd.Process.Pid)
+	assert.NotContains(t, tree, os.Getpid(), "only descendants are part of the tree")
+	assert.Greater(t, tree[cmd.Process.Pid].RSS, int64(0))
+
+	_, err := readTree(999999999)
+	assert.Error(t, err)
+}
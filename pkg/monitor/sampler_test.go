@@ -0,0 +1,28 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	start := time.Now()
+	summary := Summarize([]Sample{
+		{Time: start, CPUPercent: 50, RSS: 100, Threads: 2, ReadBytes: 10, WriteBytes: 0},
+		{Time: start.Add(time.Second), CPUPercent: 350, RSS: 400, Threads: 9, ReadBytes: 20, WriteBytes: 5},
+		{Time: start.Add(2 * time.Second), CPUPercent: 100, RSS: 300, Threads: 4, ReadBytes: 30, WriteBytes: 50},
+	})
+	assert.Equal(t, SampleSummary{
+		Samples:     3,
+		AvgCPU:      500.0 / 3,
+		PeakCPU:     350,
+		PeakRSS:     400,
+		PeakThreads: 9,
+		ReadBytes:   30,
+		WriteBytes:  50,
+	}, summary)
+
+	assert.Equal(t, SampleSummary{}, Summarize(nil))
+}
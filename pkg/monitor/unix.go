@@ -1,24 +1,44 @@
-//go:build !(linux && 386) && !windows
+//go:build !windows
 
 package monitor
 
 import (
-	"os/exec"
+	"os"
+	"runtime"
 	"syscall"
+	"time"
 )
 
-// GetCPU returns the CPU time in nanoseconds
-func GetCPU() (int64, error) {
-	usage := new(syscall.Rusage)
-	syscall.Getrusage(syscall.RUSAGE_CHILDREN, usage)
-	out := usage.Utime.Nano() + usage.Stime.Nano()
-	return out, nil
+// GetUsage returns the resources used by a finished child process. Only that
+// process and the children it waited for are counted.
+func GetUsage(state *os.ProcessState) (*Usage, error) {
+	if state == nil {
+		return nil, ErrCallNotSupported
+	}
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return nil, ErrCallNotSupported
+	}
+	// the conversions are needed on 32-bit platforms, where the fields are int32
+	return &Usage{
+		UserTime:               time.Duration(rusage.Utime.Nano()),
+		SystemTime:             time.Duration(rusage.Stime.Nano()),
+		MaxRSS:                 int64(rusage.Maxrss) * maxRSSUnit(),
+		BlockInputOps:          int64(rusage.Inblock),
+		BlockOutputOps:         int64(rusage.Oublock),
+		VoluntaryCtxSwitches:   int64(rusage.Nvcsw),
+		InvoluntaryCtxSwitches: int64(rusage.Nivcsw),
+		MajorPageFaults:        int64(rusage.Majflt),
+	}, nil
 }
 
-func GetMemoryFromCmd(cmd *exec.Cmd) (int64, error) {
-	rusage, ok := cmd.ProcessState.SysUsage().(*syscall.Rusage)
-	if !ok {
-		return 0, ErrCallNotSupported
+// maxRSSUnit is the unit of ru_maxrss in bytes: kilobytes on Linux and the
+// BSDs, but bytes on Apple platforms.
+func maxRSSUnit() int64 {
+	switch runtime.GOOS {
+	case "darwin", "ios":
+		return 1
+	default:
+		return 1024
 	}
-	return rusage.Maxrss, nil
 }
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package monitor
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUsage(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "i=0; while [ $i -lt 20000 ]; do i=$((i+1)); done")
+	require.NoError(t, cmd.Run())
+
+	usage, err := GetUsage(cmd.ProcessState)
+	require.NoError(t, err)
+	assert.Greater(t, usage.CPUTime(), time.Duration(0))
+	assert.Equal(t, usage.UserTime+usage.SystemTime, usage.CPUTime())
+	// a shell's peak RSS is somewhere between a few hundred KiB and a few
+	// dozen MiB; a unit mix-up would be off by a factor of 1024
+	assert.Greater(t, usage.MaxRSS, int64(256*1024))
+	assert.Less(t, usage.MaxRSS, int64(256*1024*1024))
+
+	_, err = GetUsage(nil)
+	assert.ErrorIs(t, err, ErrCallNotSupported)
+}
@@ -0,0 +1,25 @@
+package monitor
+
+import "time"
+
+// Usage is the resources a finished child process used, from its rusage.
+type Usage struct {
+	UserTime   time.Duration
+	SystemTime time.Duration
+	// MaxRSS is the peak resident set size in bytes.
+	MaxRSS int64
+	// BlockInputOps and BlockOutputOps count filesystem block I/O as reported by
+	// the kernel (512-byte blocks on Linux), not bytes.
+	BlockInputOps  int64
+	BlockOutputOps int64
+	// VoluntaryCtxSwitches happen when the process waits (e.g. on I/O);
+	// InvoluntaryCtxSwitches when the scheduler preempts it.
+	VoluntaryCtxSwitches   int64
+	InvoluntaryCtxSwitches int64
+	MajorPageFaults        int64
+}
+
+// CPUTime is the total time spent on the CPU, in user and kernel mode.
+func (u *Usage) CPUTime() time.Duration {
+	return u.UserTime + u.SystemTime
+}
@@ -3,16 +3,30 @@
 package monitor
 
 import (
-	"os/exec"
+	"os"
 )
 
-// GetCPU returns the CPU time in nanoseconds
-func GetCPU() (int64, error) {
+func GetUsage(state *os.ProcessState) (*Usage, error) {
 	// not supported (for now?)
-	return 0, ErrIsWindows
+	return nil, ErrIsWindows
 }
 
-func GetMemoryFromCmd(cmd *exec.Cmd) (int64, error) {
+func GetRSS(pid int) (int64, error) {
 	// not supported (for now?)
 	return 0, ErrIsWindows
 }
+
+func GetProcess(pid int) (*Process, error) {
+	// not supported (for now?)
+	return nil, ErrIsWindows
+}
+
+func FindProcesses(name string) ([]int, error) {
+	// not supported (for now?)
+	return nil, ErrIsWindows
+}
+
+func readTree(root int) (map[int]treeProcess, error) {
+	// not supported (for now?)
+	return nil, ErrIsWindows
+}
@@ -2,9 +2,7 @@ package notifier
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"strings"
 	"time"
 
@@ -16,6 +14,9 @@ import (
 type CustomNotifier struct {
 	cfg      *config.CustomConfig
 	restyCli *resty.Client
+	// initErr holds config errors found while building restyCli (e.g. an
+	// unreadable client certificate) so that they surface as a delivery error.
+	initErr error
 }
 
 var (
@@ -35,15 +36,18 @@ func (n *CustomNotifier) Notify(ctx context.Context, msg string) error {
 	if cfg.TargetUrl == "" {
 		return ErrCustomMissingTargetUrl
 	}
-	if cfg.PayloadTemplate == "" {
-		return ErrCustomMissingPayloadTemplate
+	if n.initErr != nil {
+		return n.initErr
 	}
 
-	// Replace {{message}} placeholder in payload template
-	if !strings.Contains(cfg.PayloadTemplate, "{{message}}") {
-		return ErrCustomInvalidPayloadTemplate
+	body, contentType, err := n.buildBody(msg)
+	if err != nil {
+		return err
+	}
+	responseMatcher, err := newCustomResponseMatcher(cfg.Response)
+	if err != nil {
+		return err
 	}
-	payloadStr := strings.Replace(cfg.PayloadTemplate, "{{message}}", msg, 1)
 
 	// Determine HTTP method (default to POST, case-insensitive)
 	method := strings.ToUpper(cfg.Method)
@@ -61,26 +65,30 @@ func (n *CustomNotifier) Notify(ctx context.Context, msg string) error {
 	}
 
 	// Prepare request
-	req := n.restyCli.R().SetContext(ctx)
+	req := n.restyCli.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", contentType).
+		SetBody(body)
+
+	// Apply templated query parameters
+	for key, value := range cfg.Query {
+		req = req.SetQueryParam(key, renderCustomTemplate(value, msg))
+	}
 
 	// Apply custom headers
 	for key, value := range cfg.Headers {
 		req = req.SetHeader(key, value)
 	}
 
-	// Try to parse as JSON first, if it fails, send as plain text
-	var payload interface{}
-	if err := json.Unmarshal([]byte(payloadStr), &payload); err == nil {
-		// Valid JSON - send as JSON
-		req = req.SetBody(payload)
-	} else {
-		// Not JSON - send as plain text
-		req = req.SetBody(payloadStr)
+	if err := n.applyAuth(ctx, req); err != nil {
+		return err
+	}
+	if err := n.applySigning(req, body); err != nil {
+		return err
 	}
 
 	// Make the HTTP request
 	var resp *resty.Response
-	var err error
 	switch method {
 	case "GET":
 		resp, err = req.Get(cfg.TargetUrl)
@@ -103,18 +111,25 @@ func (n *CustomNotifier) Notify(ctx context.Context, msg string) error {
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode() >= 400 {
-		return fmt.Errorf("failed to call custom webhook: %s", resp.String())
+	return responseMatcher.check(resp)
+}
+
+// MaxMessageLength is configurable since the limit depends on the receiving service.
+func (n *CustomNotifier) MaxMessageLength() int {
+	if n.cfg == nil {
+		return 0
 	}
-	return nil
+	return n.cfg.MaxMessageLength
 }
 
 func NewCustomNotifierFromConfig(cfg config.CustomConfig) Notifier {
+	restyCli := resty.New().
+		SetRetryCount(3).
+		SetLogger(&restyutils.RestyLogger{}).
+		SetTimeout(10 * time.Second)
 	return &CustomNotifier{
-		cfg: &cfg,
-		restyCli: resty.New().
-			SetRetryCount(3).
-			SetLogger(&restyutils.RestyLogger{}).
-			SetTimeout(10 * time.Second),
+		cfg:      &cfg,
+		restyCli: restyCli,
+		initErr:  configureCustomTransport(restyCli, cfg),
 	}
 }
@@ -0,0 +1,229 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/lba-studio/n-cli/internal/config"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultSigningHeader          = "X-Signature"
+	defaultSigningTimestampHeader = "X-Timestamp"
+	defaultSigningFormat          = "{{timestamp}}.{{body}}"
+	signingTimestampPlaceholder   = "{{timestamp}}"
+	signingBodyPlaceholder        = "{{body}}"
+)
+
+var (
+	ErrCustomInvalidAuthType      = errors.New("invalid auth type in custom config (expected basic, bearer or oauth2)")
+	ErrCustomMissingAuthFields    = errors.New("missing required auth fields in custom config")
+	ErrCustomMissingSigningSecret = errors.New("missing secret in custom signing config")
+	ErrCustomInvalidSigningAlgo   = errors.New("invalid signing algorithm in custom config (expected sha1, sha256 or sha512)")
+	ErrCustomInvalidSigningEnc    = errors.New("invalid signing encoding in custom config (expected hex or base64)")
+)
+
+// signingNow is swapped out in tests so that signatures are deterministic.
+var signingNow = time.Now
+
+// tokenNow is swapped out in tests to expire cached oauth2 tokens.
+var tokenNow = time.Now
+
+func (n *CustomNotifier) applyAuth(ctx context.Context, req *resty.Request) error {
+	auth := n.cfg.Auth
+	if auth == nil {
+		return nil
+	}
+	switch strings.ToLower(auth.Type) {
+	case config.CustomAuthBasic:
+		if auth.Username == "" {
+			return ErrCustomMissingAuthFields
+		}
+		req.SetBasicAuth(auth.Username, auth.Password)
+	case config.CustomAuthBearer:
+		if auth.Token == "" {
+			return ErrCustomMissingAuthFields
+		}
+		req.SetAuthToken(auth.Token)
+	case config.CustomAuthOAuth2Credentials:
+		token, err := n.oauth2Token(ctx, auth)
+		if err != nil {
+			return err
+		}
+		req.SetAuthToken(token)
+	default:
+		return ErrCustomInvalidAuthType
+	}
+	return nil
+}
+
+type oauth2TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type cachedOAuth2Token struct {
+	accessToken string
+	expiresAt   time.Time
+}
+
+// oauth2TokenCache holds the tokens fetched so far. The zero value is ready
+// to use.
+type oauth2TokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]cachedOAuth2Token
+	// fetches makes concurrent requests for the same token share one fetch,
+	// without holding mu while it is in flight
+	fetches singleflight.Group
+}
+
+// oauth2Tokens is shared by all notifiers, since NotifyTo creates new ones for
+// every notification. Tokens are keyed by token URL, client ID, audience and
+// scopes.
+var oauth2Tokens = &oauth2TokenCache{}
+
+func (c *oauth2TokenCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.tokens[key]
+	if !ok || !tokenNow().Before(cached.expiresAt) {
+		return "", false
+	}
+	return cached.accessToken, true
+}
+
+func (c *oauth2TokenCache) put(key string, token cachedOAuth2Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == nil {
+		c.tokens = map[string]cachedOAuth2Token{}
+	}
+	c.tokens[key] = token
+}
+
+// oauth2TokenExpiryLeeway refreshes tokens slightly before they expire so that
+// a token does not lapse between being fetched and being used.
+const oauth2TokenExpiryLeeway = 30 * time.Second
+
+func (n *CustomNotifier) oauth2Token(ctx context.Context, auth *config.CustomAuthConfig) (string, error) {
+	if auth.TokenUrl == "" || auth.ClientId == "" {
+		return "", ErrCustomMissingAuthFields
+	}
+	cacheKey := strings.Join([]string{auth.TokenUrl, auth.ClientId, auth.Audience, strings.Join(auth.Scopes, " ")}, "|")
+
+	if token, ok := oauth2Tokens.get(cacheKey); ok {
+		return token, nil
+	}
+	token, err, _ := oauth2Tokens.fetches.Do(cacheKey, func() (any, error) {
+		return n.fetchOAuth2Token(ctx, auth, cacheKey)
+	})
+	if err != nil {
+		return "", err
+	}
+	return token.(string), nil
+}
+
+func (n *CustomNotifier) fetchOAuth2Token(ctx context.Context, auth *config.CustomAuthConfig, cacheKey string) (string, error) {
+	form := map[string]string{"grant_type": "client_credentials"}
+	if len(auth.Scopes) > 0 {
+		form["scope"] = strings.Join(auth.Scopes, " ")
+	}
+	if auth.Audience != "" {
+		form["audience"] = auth.Audience
+	}
+	resp, err := n.restyCli.R().
+		SetContext(ctx).
+		SetBasicAuth(auth.ClientId, auth.ClientSecret).
+		SetFormData(form).
+		SetResult(oauth2TokenResponse{}).
+		Post(auth.TokenUrl)
+	if err != nil {
+		return "", fmt.Errorf("fetch oauth2 token: %w", err)
+	}
+	if resp.StatusCode() >= 400 {
+		return "", fmt.Errorf("failed to fetch oauth2 token: %s", resp.String())
+	}
+	tokenResp := resp.Result().(*oauth2TokenResponse)
+	if tokenResp.AccessToken == "" {
+		return "", fmt.Errorf("oauth2 token response has no access_token: %s", resp.String())
+	}
+
+	if tokenResp.ExpiresIn > 0 {
+		oauth2Tokens.put(cacheKey, cachedOAuth2Token{
+			accessToken: tokenResp.AccessToken,
+			expiresAt:   tokenNow().Add(time.Duration(tokenResp.ExpiresIn)*time.Second - oauth2TokenExpiryLeeway),
+		})
+	}
+	return tokenResp.AccessToken, nil
+}
+
+func (n *CustomNotifier) applySigning(req *resty.Request, body []byte) error {
+	signing := n.cfg.Signing
+	if signing == nil {
+		return nil
+	}
+	if signing.Secret == "" {
+		return ErrCustomMissingSigningSecret
+	}
+
+	var newHash func() hash.Hash
+	switch strings.ToLower(signing.Algorithm) {
+	case "", "sha256":
+		newHash = sha256.New
+	case "sha1":
+		newHash = sha1.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return ErrCustomInvalidSigningAlgo
+	}
+
+	format := signing.Format
+	if format == "" {
+		format = defaultSigningFormat
+	}
+	timestamp := strconv.FormatInt(signingNow().Unix(), 10)
+	signedPayload := strings.ReplaceAll(format, signingTimestampPlaceholder, timestamp)
+	signedPayload = strings.ReplaceAll(signedPayload, signingBodyPlaceholder, string(body))
+
+	mac := hmac.New(newHash, []byte(signing.Secret))
+	mac.Write([]byte(signedPayload))
+	sum := mac.Sum(nil)
+
+	var signature string
+	switch strings.ToLower(signing.Encoding) {
+	case "", "hex":
+		signature = hex.EncodeToString(sum)
+	case "base64":
+		signature = base64.StdEncoding.EncodeToString(sum)
+	default:
+		return ErrCustomInvalidSigningEnc
+	}
+
+	header := signing.Header
+	if header == "" {
+		header = defaultSigningHeader
+	}
+	timestampHeader := signing.TimestampHeader
+	if timestampHeader == "" {
+		timestampHeader = defaultSigningTimestampHeader
+	}
+	req.SetHeader(header, signing.Prefix+signature)
+	req.SetHeader(timestampHeader, timestamp)
+	return nil
+}
@@ -0,0 +1,236 @@
+package notifier
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/jarcoal/httpmock"
+	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const customAuthTestUrl = "https://api.example.com/webhook"
+
+func stubSigningNow(t *testing.T, now time.Time) {
+	t.Helper()
+	original := signingNow
+	signingNow = func() time.Time { return now }
+	t.Cleanup(func() {
+		signingNow = original
+	})
+}
+
+func TestCustomNotifierAuth(t *testing.T) {
+	testRestyClient := resty.New()
+	httpmock.ActivateNonDefault(testRestyClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	type testCase struct {
+		name       string
+		auth       *config.CustomAuthConfig
+		wantHeader string
+		wantErr    error
+	}
+	testCases := []testCase{
+		{
+			name:       "basic auth",
+			auth:       &config.CustomAuthConfig{Type: "basic", Username: "user", Password: "pass"},
+			wantHeader: "Basic dXNlcjpwYXNz",
+		},
+		{
+			name:       "bearer token",
+			auth:       &config.CustomAuthConfig{Type: "Bearer", Token: "token123"},
+			wantHeader: "Bearer token123",
+		},
+		{
+			name:    "sad path - bearer without token",
+			auth:    &config.CustomAuthConfig{Type: "bearer"},
+			wantErr: ErrCustomMissingAuthFields,
+		},
+		{
+			name:    "sad path - unknown auth type",
+			auth:    &config.CustomAuthConfig{Type: "kerberos"},
+			wantErr: ErrCustomInvalidAuthType,
+		},
+	}
+
+	for _, tc := range testCases {
+		httpmock.Reset()
+		t.Run(tc.name, func(t *testing.T) {
+			var gotHeader string
+			httpmock.RegisterResponder("POST", customAuthTestUrl, func(req *http.Request) (*http.Response, error) {
+				gotHeader = req.Header.Get("Authorization")
+				return httpmock.NewStringResponse(200, ""), nil
+			})
+			notifier := &CustomNotifier{
+				cfg: &config.CustomConfig{
+					TargetUrl:       customAuthTestUrl,
+					PayloadTemplate: `{"text": "{{message}}"}`,
+					Auth:            tc.auth,
+				},
+				restyCli: testRestyClient,
+			}
+			err := notifier.Notify(context.Background(), "my notification")
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantErr != nil {
+				assert.Equal(t, 0, httpmock.GetTotalCallCount())
+				return
+			}
+			assert.Equal(t, tc.wantHeader, gotHeader)
+		})
+	}
+}
+
+func TestCustomNotifierOAuth2TokenIsCached(t *testing.T) {
+	testRestyClient := resty.New()
+	httpmock.ActivateNonDefault(testRestyClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	now := time.Unix(1700000000, 0)
+	original := tokenNow
+	tokenNow = func() time.Time { return now }
+	t.Cleanup(func() { tokenNow = original })
+	oauth2Tokens = &oauth2TokenCache{}
+
+	tokenUrl := "https://auth.example.com/oauth2/token"
+	var gotGrant, gotScope, gotClientAuth string
+	httpmock.RegisterResponder("POST", tokenUrl, func(req *http.Request) (*http.Response, error) {
+		require.NoError(t, req.ParseForm())
+		gotGrant = req.PostForm.Get("grant_type")
+		gotScope = req.PostForm.Get("scope")
+		gotClientAuth = req.Header.Get("Authorization")
+		return httpmock.NewJsonResponse(200, map[string]any{
+			"access_token": "fetched-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+	var gotHeaders []string
+	httpmock.RegisterResponder("POST", customAuthTestUrl, func(req *http.Request) (*http.Response, error) {
+		gotHeaders = append(gotHeaders, req.Header.Get("Authorization"))
+		return httpmock.NewStringResponse(200, ""), nil
+	})
+
+	cfg := config.CustomConfig{
+		TargetUrl:       customAuthTestUrl,
+		PayloadTemplate: `{"text": "{{message}}"}`,
+		Auth: &config.CustomAuthConfig{
+			Type:         "oauth2",
+			TokenUrl:     tokenUrl,
+			ClientId:     "cached-client",
+			ClientSecret: "secret",
+			Scopes:       []string{"notify", "write"},
+		},
+	}
+	// NotifyTo creates a new notifier for every notification
+	notify := func() error {
+		notifier := &CustomNotifier{cfg: &cfg, restyCli: testRestyClient}
+		return notifier.Notify(context.Background(), "my notification")
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, notify())
+	}
+
+	info := httpmock.GetCallCountInfo()
+	assert.Equal(t, 1, info["POST "+tokenUrl])
+	assert.Equal(t, 2, info["POST "+customAuthTestUrl])
+
+	// refreshed shortly before it expires
+	now = now.Add(3600*time.Second - oauth2TokenExpiryLeeway)
+	require.NoError(t, notify())
+	info = httpmock.GetCallCountInfo()
+	assert.Equal(t, 2, info["POST "+tokenUrl])
+	assert.Equal(t, "client_credentials", gotGrant)
+	assert.Equal(t, "notify write", gotScope)
+	assert.Equal(t, "Basic Y2FjaGVkLWNsaWVudDpzZWNyZXQ=", gotClientAuth)
+	assert.Equal(t, []string{"Bearer fetched-token", "Bearer fetched-token", "Bearer fetched-token"}, gotHeaders)
+}
+
+func TestCustomNotifierSigning(t *testing.T) {
+	testRestyClient := resty.New()
+	httpmock.ActivateNonDefault(testRestyClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	stubSigningNow(t, time.Unix(1700000000, 0))
+
+	type testCase struct {
+		name          string
+		signing       *config.CustomSigningConfig
+		wantHeader    string
+		wantSignature string
+		wantTimestamp string
+		wantErr       error
+	}
+	testCases := []testCase{
+		{
+			name:          "defaults - hex sha256 over timestamp.body",
+			signing:       &config.CustomSigningConfig{Secret: "s3cret"},
+			wantHeader:    "X-Signature",
+			wantSignature: "96b5a08779f40426a01cbea36d9c678416b3ac7c1077d41f780cd2b9764bf6c7",
+			wantTimestamp: "1700000000",
+		},
+		{
+			name: "custom header, format, encoding and prefix",
+			signing: &config.CustomSigningConfig{
+				Secret:          "s3cret",
+				Header:          "X-Hub-Signature-256",
+				TimestampHeader: "X-Hub-Timestamp",
+				Format:          "{{body}}",
+				Encoding:        "base64",
+				Prefix:          "sha256=",
+			},
+			wantHeader:    "X-Hub-Signature-256",
+			wantSignature: "sha256=UW46v049YwqlZVbZKMy6BvZA2I7MR0qSoH9nuJVueUs=",
+			wantTimestamp: "1700000000",
+		},
+		{
+			name:    "sad path - missing secret",
+			signing: &config.CustomSigningConfig{},
+			wantErr: ErrCustomMissingSigningSecret,
+		},
+		{
+			name:    "sad path - unknown algorithm",
+			signing: &config.CustomSigningConfig{Secret: "s3cret", Algorithm: "md5"},
+			wantErr: ErrCustomInvalidSigningAlgo,
+		},
+	}
+
+	for _, tc := range testCases {
+		httpmock.Reset()
+		t.Run(tc.name, func(t *testing.T) {
+			var gotReq *http.Request
+			var gotBody string
+			httpmock.RegisterResponder("POST", customAuthTestUrl, func(req *http.Request) (*http.Response, error) {
+				gotReq = req
+				b, _ := io.ReadAll(req.Body)
+				gotBody = string(b)
+				return httpmock.NewStringResponse(200, ""), nil
+			})
+			notifier := &CustomNotifier{
+				cfg: &config.CustomConfig{
+					TargetUrl:       customAuthTestUrl,
+					PayloadTemplate: `{"text": "{{message}}"}`,
+					Signing:         tc.signing,
+				},
+				restyCli: testRestyClient,
+			}
+			err := notifier.Notify(context.Background(), "my notification")
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantErr != nil {
+				assert.Equal(t, 0, httpmock.GetTotalCallCount())
+				return
+			}
+			require.NotNil(t, gotReq)
+			assert.Equal(t, `{"text":"my notification"}`, gotBody)
+			assert.Equal(t, tc.wantSignature, gotReq.Header.Get(tc.wantHeader))
+			timestampHeader := tc.signing.TimestampHeader
+			if timestampHeader == "" {
+				timestampHeader = "X-Timestamp"
+			}
+			assert.Equal(t, tc.wantTimestamp, gotReq.Header.Get(timestampHeader))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/lba-studio/n-cli/pkg/notifier/utils"
+)
+
+var (
+	ErrCustomMissingForm     = errors.New("missing form fields in custom config")
+	ErrCustomInvalidBodyType = errors.New("invalid bodyType in custom config (expected form or multipart)")
+)
+
+func renderCustomTemplate(tmpl, msg string) string {
+	return strings.ReplaceAll(tmpl, utils.MessagePlaceholder, msg)
+}
+
+// buildBody renders the request body for msg and returns it along with its
+// content type. The body is encoded here rather than by resty so that
+// signatures cover the exact bytes that go over the wire.
+func (n *CustomNotifier) buildBody(msg string) ([]byte, string, error) {
+	cfg := n.cfg
+	switch strings.ToLower(cfg.BodyType) {
+	case "":
+		return buildTemplateBody(cfg.PayloadTemplate, msg)
+	case config.CustomBodyTypeForm:
+		if len(cfg.Form) == 0 {
+			return nil, "", ErrCustomMissingForm
+		}
+		values := url.Values{}
+		for key, value := range cfg.Form {
+			values.Set(key, renderCustomTemplate(value, msg))
+		}
+		return []byte(values.Encode()), "application/x-www-form-urlencoded", nil
+	case config.CustomBodyTypeMultipart:
+		if len(cfg.Form) == 0 {
+			return nil, "", ErrCustomMissingForm
+		}
+		keys := make([]string, 0, len(cfg.Form))
+		for key := range cfg.Form {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for _, key := range keys {
+			if err := w.WriteField(key, renderCustomTemplate(cfg.Form[key], msg)); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), w.FormDataContentType(), nil
+	default:
+		return nil, "", ErrCustomInvalidBodyType
+	}
+}
+
+func buildTemplateBody(payloadTemplate, msg string) ([]byte, string, error) {
+	if payloadTemplate == "" {
+		return nil, "", ErrCustomMissingPayloadTemplate
+	}
+
+	// Replace {{message}} placeholder in payload template
+	if !strings.Contains(payloadTemplate, utils.MessagePlaceholder) {
+		return nil, "", ErrCustomInvalidPayloadTemplate
+	}
+	payloadStr := strings.Replace(payloadTemplate, utils.MessagePlaceholder, msg, 1)
+
+	// Try to parse as JSON first, if it fails, send as plain text
+	var payload interface{}
+	if err := json.Unmarshal([]byte(payloadStr), &payload); err != nil {
+		// Not JSON - send as plain text
+		return []byte(payloadStr), "text/plain; charset=utf-8", nil
+	}
+	// Valid JSON - send as JSON
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, "application/json", nil
+}
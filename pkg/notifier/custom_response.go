@@ -0,0 +1,142 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/lba-studio/n-cli/internal/config"
+)
+
+var (
+	ErrCustomInvalidBodyRegex = errors.New("invalid bodyRegex in custom response config")
+	ErrCustomInvalidJSONPath  = errors.New("invalid jsonPath in custom response config")
+)
+
+// customResponseMatcher checks webhook responses against the rules in a
+// CustomResponseConfig. Without rules, any status below 400 is a success.
+type customResponseMatcher struct {
+	cfg       config.CustomResponseConfig
+	bodyRegex *regexp.Regexp
+	jsonPath  []string
+}
+
+func newCustomResponseMatcher(cfg *config.CustomResponseConfig) (*customResponseMatcher, error) {
+	m := &customResponseMatcher{}
+	if cfg == nil {
+		return m, nil
+	}
+	m.cfg = *cfg
+	if cfg.BodyRegex != "" {
+		re, err := regexp.Compile(cfg.BodyRegex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCustomInvalidBodyRegex, err.Error())
+		}
+		m.bodyRegex = re
+	}
+	if cfg.JSONPath != "" {
+		path, err := parseJSONPath(cfg.JSONPath)
+		if err != nil {
+			return nil, err
+		}
+		m.jsonPath = path
+	}
+	return m, nil
+}
+
+func (m *customResponseMatcher) check(resp *resty.Response) error {
+	status := resp.StatusCode()
+	if len(m.cfg.StatusCodes) > 0 {
+		if !containsInt(m.cfg.StatusCodes, status) {
+			return fmt.Errorf("failed to call custom webhook: status %d not in %v: %s", status, m.cfg.StatusCodes, resp.String())
+		}
+	} else if status >= 400 {
+		return fmt.Errorf("failed to call custom webhook: %s", resp.String())
+	}
+
+	if m.jsonPath != nil {
+		got, err := lookupJSONPath(resp.Body(), m.jsonPath)
+		if err != nil {
+			return fmt.Errorf("unexpected custom webhook response: %s: %s", err.Error(), resp.String())
+		}
+		if got != m.cfg.Equals {
+			return fmt.Errorf("unexpected custom webhook response: %s is %s, expected %s: %s", m.cfg.JSONPath, got, m.cfg.Equals, resp.String())
+		}
+	}
+
+	if m.bodyRegex != nil && !m.bodyRegex.Match(resp.Body()) {
+		return fmt.Errorf("unexpected custom webhook response: body does not match /%s/: %s", m.cfg.BodyRegex, resp.String())
+	}
+	return nil
+}
+
+func containsInt(list []int, v int) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// parseJSONPath splits a dot-notation path such as "$.result.items[0].ok" into
+// its segments. Only object keys and array indexes are supported.
+func parseJSONPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+	if path == "" {
+		return nil, ErrCustomInvalidJSONPath
+	}
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, ErrCustomInvalidJSONPath
+		}
+	}
+	return segments, nil
+}
+
+// lookupJSONPath returns the value at path in body, rendered the way it would
+// be written in YAML config: strings without quotes, everything else as JSON.
+func lookupJSONPath(body []byte, path []string) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var current interface{}
+	if err := decoder.Decode(&current); err != nil {
+		return "", errors.New("body is not JSON")
+	}
+
+	for i, segment := range path {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return "", fmt.Errorf("%s is missing", strings.Join(path[:i+1], "."))
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", fmt.Errorf("%s is missing", strings.Join(path[:i+1], "."))
+			}
+			current = node[index]
+		default:
+			return "", fmt.Errorf("%s is missing", strings.Join(path[:i+1], "."))
+		}
+	}
+
+	if s, ok := current.(string); ok {
+		return s, nil
+	}
+	out, err := json.Marshal(current)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
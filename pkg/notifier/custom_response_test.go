@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/jarcoal/httpmock"
+	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomNotifierResponseValidation(t *testing.T) {
+	testRestyClient := resty.New()
+	httpmock.ActivateNonDefault(testRestyClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	targetUrl := "https://api.example.com/webhook"
+
+	type testCase struct {
+		name                 string
+		response             *config.CustomResponseConfig
+		status               int
+		body                 string
+		wantErr              error
+		shouldAPINotBeCalled bool
+	}
+	testCases := []testCase{
+		{
+			name:     "happy path - expected status code",
+			response: &config.CustomResponseConfig{StatusCodes: []int{201, 202}},
+			status:   202,
+		},
+		{
+			name:     "happy path - json path equals",
+			response: &config.CustomResponseConfig{JSONPath: "ok", Equals: "true"},
+			status:   200,
+			body:     `{"ok": true}`,
+		},
+		{
+			name:     "happy path - nested json path with array index",
+			response: &config.CustomResponseConfig{JSONPath: "$.result.items[1].state", Equals: "queued"},
+			status:   200,
+			body:     `{"result": {"items": [{"state": "sent"}, {"state": "queued"}]}}`,
+		},
+		{
+			name:     "happy path - body regex",
+			response: &config.CustomResponseConfig{BodyRegex: `^accepted\b`},
+			status:   200,
+			body:     "accepted (id=42)",
+		},
+		{
+			name:     "sad path - unexpected status code",
+			response: &config.CustomResponseConfig{StatusCodes: []int{201}},
+			status:   200,
+			body:     "ok",
+			wantErr:  errors.New("failed to call custom webhook: status 200 not in [201]: ok"),
+		},
+		{
+			name:     "sad path - json path mismatch",
+			response: &config.CustomResponseConfig{JSONPath: "ok", Equals: "true"},
+			status:   200,
+			body:     `{"ok":false,"error":"channel_not_found"}`,
+			wantErr:  errors.New(`unexpected custom webhook response: ok is false, expected true: {"ok":false,"error":"channel_not_found"}`),
+		},
+		{
+			name:     "sad path - json path missing",
+			response: &config.CustomResponseConfig{JSONPath: "result.ok", Equals: "true"},
+			status:   200,
+			body:     `{"error":"nope"}`,
+			wantErr:  errors.New(`unexpected custom webhook response: result is missing: {"error":"nope"}`),
+		},
+		{
+			name:     "sad path - body is not JSON",
+			response: &config.CustomResponseConfig{JSONPath: "ok", Equals: "true"},
+			status:   200,
+			body:     "<html>oops</html>",
+			wantErr:  errors.New("unexpected custom webhook response: body is not JSON: <html>oops</html>"),
+		},
+		{
+			name:     "sad path - body regex mismatch",
+			response: &config.CustomResponseConfig{BodyRegex: "^accepted"},
+			status:   200,
+			body:     "rejected",
+			wantErr:  errors.New("unexpected custom webhook response: body does not match /^accepted/: rejected"),
+		},
+		{
+			name:                 "sad path - invalid regex fails before calling the webhook",
+			response:             &config.CustomResponseConfig{BodyRegex: "("},
+			wantErr:              errors.New("invalid bodyRegex in custom response config: error parsing regexp: missing closing ): `(`"),
+			shouldAPINotBeCalled: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		httpmock.Reset()
+		t.Run(tc.name, func(t *testing.T) {
+			httpmock.RegisterResponder("POST", targetUrl, httpmock.NewStringResponder(tc.status, tc.body))
+			notifier := &CustomNotifier{
+				cfg: &config.CustomConfig{
+					TargetUrl:       targetUrl,
+					PayloadTemplate: `{"text": "{{message}}"}`,
+					Response:        tc.response,
+				},
+				restyCli: testRestyClient,
+			}
+			err := notifier.Notify(context.Background(), "my notification")
+			if tc.shouldAPINotBeCalled {
+				assert.Equal(t, 0, httpmock.GetTotalCallCount())
+			}
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.wantErr.Error())
+		})
+	}
+}
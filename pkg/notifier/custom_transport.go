@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/lba-studio/n-cli/internal/config"
+)
+
+var (
+	ErrCustomIncompleteClientCert = errors.New("tls certFile and keyFile must be set together in custom config")
+	ErrCustomInvalidProxy         = errors.New("invalid proxy in custom config (expected http, https, socks5 or socks5h URL)")
+)
+
+// configureCustomTransport applies the TLS and proxy settings of cfg to restyCli.
+func configureCustomTransport(restyCli *resty.Client, cfg config.CustomConfig) error {
+	if cfg.TLS != nil {
+		tlsConfig, err := customTLSConfig(*cfg.TLS)
+		if err != nil {
+			return err
+		}
+		restyCli.SetTLSClientConfig(tlsConfig)
+	}
+
+	if cfg.Proxy != "" {
+		proxyUrl, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyUrl.Host == "" {
+			return ErrCustomInvalidProxy
+		}
+		switch proxyUrl.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return ErrCustomInvalidProxy
+		}
+		restyCli.SetProxy(proxyUrl.String())
+	}
+	return nil
+}
+
+func customTLSConfig(cfg config.CustomTLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, ErrCustomIncompleteClientCert
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.CAFile != "" {
+		caPEM, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read caFile: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("caFile %s contains no PEM certificates", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,198 @@
+package notifier
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/jarcoal/httpmock"
+	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomNotifierBodiesAndQuery(t *testing.T) {
+	testRestyClient := resty.New()
+	httpmock.ActivateNonDefault(testRestyClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	targetUrl := "https://api.example.com/webhook"
+
+	t.Run("templated query params", func(t *testing.T) {
+		httpmock.Reset()
+		var gotQuery map[string][]string
+		httpmock.RegisterResponder("POST", targetUrl, func(req *http.Request) (*http.Response, error) {
+			gotQuery = req.URL.Query()
+			return httpmock.NewStringResponse(200, ""), nil
+		})
+		notifier := &CustomNotifier{
+			cfg: &config.CustomConfig{
+				TargetUrl:       targetUrl,
+				PayloadTemplate: `{"text": "{{message}}"}`,
+				Query: map[string]string{
+					"title":  "n-cli: {{message}}",
+					"source": "n-cli",
+				},
+			},
+			restyCli: testRestyClient,
+		}
+		require.NoError(t, notifier.Notify(context.Background(), "build done & dusted"))
+		assert.Equal(t, []string{"n-cli: build done & dusted"}, gotQuery["title"])
+		assert.Equal(t, []string{"n-cli"}, gotQuery["source"])
+	})
+
+	t.Run("form body", func(t *testing.T) {
+		httpmock.Reset()
+		var gotContentType, gotText, gotToken string
+		httpmock.RegisterResponder("POST", targetUrl, func(req *http.Request) (*http.Response, error) {
+			gotContentType = req.Header.Get("Content-Type")
+			require.NoError(t, req.ParseForm())
+			gotText = req.PostForm.Get("text")
+			gotToken = req.PostForm.Get("token")
+			return httpmock.NewStringResponse(200, ""), nil
+		})
+		notifier := &CustomNotifier{
+			cfg: &config.CustomConfig{
+				TargetUrl: targetUrl,
+				BodyType:  "form",
+				Form: map[string]string{
+					"text":  "{{message}}",
+					"token": "abc",
+				},
+			},
+			restyCli: testRestyClient,
+		}
+		require.NoError(t, notifier.Notify(context.Background(), "my notification"))
+		assert.Equal(t, "application/x-www-form-urlencoded", gotContentType)
+		assert.Equal(t, "my notification", gotText)
+		assert.Equal(t, "abc", gotToken)
+	})
+
+	t.Run("multipart body", func(t *testing.T) {
+		httpmock.Reset()
+		var gotFields map[string]string
+		httpmock.RegisterResponder("POST", targetUrl, func(req *http.Request) (*http.Response, error) {
+			mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+			require.NoError(t, err)
+			assert.Equal(t, "multipart/form-data", mediaType)
+			gotFields = map[string]string{}
+			reader := multipart.NewReader(req.Body, params["boundary"])
+			for {
+				part, err := reader.NextPart()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				value, _ := io.ReadAll(part)
+				gotFields[part.FormName()] = string(value)
+			}
+			return httpmock.NewStringResponse(200, ""), nil
+		})
+		notifier := &CustomNotifier{
+			cfg: &config.CustomConfig{
+				TargetUrl: targetUrl,
+				BodyType:  "multipart",
+				Form: map[string]string{
+					"message": "{{message}}",
+					"title":   "n-cli",
+				},
+			},
+			restyCli: testRestyClient,
+		}
+		require.NoError(t, notifier.Notify(context.Background(), "my notification"))
+		assert.Equal(t, map[string]string{"message": "my notification", "title": "n-cli"}, gotFields)
+	})
+
+	t.Run("sad path - form body without fields", func(t *testing.T) {
+		httpmock.Reset()
+		notifier := &CustomNotifier{
+			cfg:      &config.CustomConfig{TargetUrl: targetUrl, BodyType: "form"},
+			restyCli: testRestyClient,
+		}
+		assert.Equal(t, ErrCustomMissingForm, notifier.Notify(context.Background(), "my notification"))
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("sad path - unknown body type", func(t *testing.T) {
+		httpmock.Reset()
+		notifier := &CustomNotifier{
+			cfg:      &config.CustomConfig{TargetUrl: targetUrl, BodyType: "xml", Form: map[string]string{"a": "b"}},
+			restyCli: testRestyClient,
+		}
+		assert.Equal(t, ErrCustomInvalidBodyType, notifier.Notify(context.Background(), "my notification"))
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	})
+}
+
+func TestCustomNotifierTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0600))
+
+	newNotifier := func(tlsCfg *config.CustomTLSConfig) Notifier {
+		return NewCustomNotifierFromConfig(config.CustomConfig{
+			TargetUrl:       server.URL,
+			PayloadTemplate: `{"text": "{{message}}"}`,
+			TLS:             tlsCfg,
+		})
+	}
+
+	t.Run("untrusted server certificate fails", func(t *testing.T) {
+		notifier := newNotifier(nil)
+		assert.Error(t, notifier.Notify(context.Background(), "my notification"))
+	})
+
+	t.Run("custom CA bundle", func(t *testing.T) {
+		notifier := newNotifier(&config.CustomTLSConfig{CAFile: caFile})
+		assert.NoError(t, notifier.Notify(context.Background(), "my notification"))
+	})
+
+	t.Run("insecureSkipVerify", func(t *testing.T) {
+		notifier := newNotifier(&config.CustomTLSConfig{InsecureSkipVerify: true})
+		assert.NoError(t, notifier.Notify(context.Background(), "my notification"))
+	})
+
+	t.Run("sad path - cert without key", func(t *testing.T) {
+		notifier := newNotifier(&config.CustomTLSConfig{CertFile: caFile})
+		assert.Equal(t, ErrCustomIncompleteClientCert, notifier.Notify(context.Background(), "my notification"))
+	})
+
+	t.Run("sad path - missing CA file", func(t *testing.T) {
+		notifier := newNotifier(&config.CustomTLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+		assert.ErrorContains(t, notifier.Notify(context.Background(), "my notification"), "read caFile")
+	})
+}
+
+func TestConfigureCustomTransportProxy(t *testing.T) {
+	testCases := []struct {
+		proxy   string
+		wantErr error
+	}{
+		{proxy: "http://proxy.internal:3128"},
+		{proxy: "socks5://127.0.0.1:1080"},
+		{proxy: "ftp://proxy.internal", wantErr: ErrCustomInvalidProxy},
+		{proxy: "not a url", wantErr: ErrCustomInvalidProxy},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.proxy, func(t *testing.T) {
+			restyCli := resty.New()
+			err := configureCustomTransport(restyCli, config.CustomConfig{Proxy: tc.proxy})
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantErr == nil {
+				assert.True(t, restyCli.IsProxySet())
+			}
+		})
+	}
+}
@@ -13,6 +13,10 @@ import (
 	restyutils "github.com/lba-studio/n-cli/pkg/resty_utils"
 )
 
+// Discord rejects message content longer than 2000 characters. The limit applies
+// once messageFormat is filled in, so Notify truncates rather than NotifyTo.
+const discordMaxMessageLength = 2000
+
 type DiscordNotifier struct {
 	restyCli   *resty.Client
 	configurer config.Configurer
@@ -40,7 +44,7 @@ func (n *DiscordNotifier) Notify(ctx context.Context, msg string) error {
 		return webhook.ErrWebhookMissingWebhookURL
 	}
 	format := cfg.Discord.MessageFormat
-	msg, err = utils.GetMessageFromFormat(format, msg)
+	msg, err = utils.FormatMessage(format, msg, discordMaxMessageLength)
 	if err != nil {
 		return err
 	}
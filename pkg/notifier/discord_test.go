@@ -2,7 +2,10 @@ package notifier
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/go-resty/resty/v2"
@@ -105,3 +108,34 @@ func TestDiscordNotifier(t *testing.T) {
 		})
 	}
 }
+
+func TestDiscordNotifierTruncatesAfterFormat(t *testing.T) {
+	testRestyClient := resty.New()
+	httpmock.ActivateNonDefault(testRestyClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	mockConfigurer := config.NewMockConfigurer(t)
+	mockConfigurer.On("GetConfig").Return(config.Config{
+		Discord: &config.DiscordConfig{
+			WebhookURL:    "https://blah.com",
+			MessageFormat: "**n-cli** says: {{message}}",
+		}}, nil)
+	var sent string
+	httpmock.RegisterResponder("POST", "https://blah.com", func(req *http.Request) (*http.Response, error) {
+		var payload map[string]string
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return nil, err
+		}
+		sent = payload["content"]
+		return httpmock.NewStringResponse(200, ""), nil
+	})
+
+	notifier := &DiscordNotifier{
+		restyCli:   testRestyClient,
+		configurer: mockConfigurer,
+	}
+	err := notifier.Notify(context.Background(), "Command `make` FAILED.\n"+strings.Repeat("noise\n", 1000)+"error: the important bit")
+	assert.NoError(t, err)
+	assert.Len(t, []rune(sent), 2000)
+	assert.True(t, strings.HasPrefix(sent, "**n-cli** says: Command `make` FAILED."))
+	assert.True(t, strings.HasSuffix(sent, "error: the important bit"))
+}
@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/lba-studio/n-cli/pkg/formatter"
+	"github.com/lba-studio/n-cli/pkg/notifier/utils"
+)
+
+const fileTimePlaceholder = "{{time}}"
+
+var textLineEscaper = strings.NewReplacer("\r\n", `\n`, "\n", `\n`, "\r", `\r`)
+
+type FileNotifier struct {
+	cfg *config.FileConfig
+	now func() time.Time
+}
+
+type fileEntry struct {
+	Time    string `json:"time"`
+	Message string `json:"message"`
+}
+
+var (
+	ErrFileMissingConfig   = errors.New("missing file config")
+	ErrFileMissingPath     = errors.New("missing path in file config")
+	ErrFileInvalidFormat   = errors.New("invalid format in file config (expected jsonl, text or template)")
+	ErrFileMissingTemplate = errors.New("missing template in file config")
+)
+
+func (n *FileNotifier) Notify(ctx context.Context, msg string) error {
+	if n.cfg == nil {
+		return ErrFileMissingConfig
+	}
+	cfg := n.cfg
+	if cfg.Path == "" {
+		return ErrFileMissingPath
+	}
+
+	line, err := n.formatLine(msg)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(cfg.Path)
+	if err == nil && info.Mode()&os.ModeNamedPipe != 0 {
+		return writeFIFO(cfg.Path, []byte(line))
+	}
+
+	if err == nil && cfg.RotateSize != "" {
+		rotateSize, err := formatter.ParseByteSize(cfg.RotateSize)
+		if err != nil {
+			return fmt.Errorf("rotateSize: %w", err)
+		}
+		if info.Size()+int64(len(line)) > rotateSize {
+			// only one rotated file is kept, the previous one is replaced
+			if err := os.Rename(cfg.Path, cfg.Path+".1"); err != nil {
+				return fmt.Errorf("rotate %s: %w", cfg.Path, err)
+			}
+		}
+	}
+
+	f, err := os.OpenFile(cfg.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(line)
+	return err
+}
+
+func (n *FileNotifier) formatLine(msg string) (string, error) {
+	now := n.now()
+	switch strings.ToLower(n.cfg.Format) {
+	case "", config.FileFormatJSONL:
+		b, err := json.Marshal(fileEntry{
+			Time:    now.Format(time.RFC3339),
+			Message: msg,
+		})
+		if err != nil {
+			return "", err
+		}
+		return string(b) + "\n", nil
+	case config.FileFormatText:
+		// one notification per line so that line-oriented readers (e.g. tail -f)
+		// stay in sync; the rest of the whitespace keeps tables aligned
+		return textLineEscaper.Replace(strings.TrimRight(msg, "\r\n")) + "\n", nil
+	case config.FileFormatTemplate:
+		if n.cfg.Template == "" {
+			return "", ErrFileMissingTemplate
+		}
+		out, err := utils.GetMessageFromFormat(n.cfg.Template, msg)
+		if err != nil {
+			return "", err
+		}
+		out = strings.ReplaceAll(out, fileTimePlaceholder, now.Format(time.RFC3339))
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		return out, nil
+	default:
+		return "", ErrFileInvalidFormat
+	}
+}
+
+func NewFileNotifierFromConfig(cfg config.FileConfig) Notifier {
+	return &FileNotifier{
+		cfg: &cfg,
+		now: time.Now,
+	}
+}
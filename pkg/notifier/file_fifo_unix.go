@@ -0,0 +1,32 @@
+//go:build !windows
+
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// writeFIFO writes to a named pipe without ever blocking. If nobody is reading
+// from the pipe, the notification is silently dropped.
+func writeFIFO(path string, b []byte) error {
+	// os.OpenFile would hand the fd to the runtime poller, which then parks the
+	// goroutine on EAGAIN - use raw syscalls so that we never wait on the reader.
+	fd, err := syscall.Open(path, syscall.O_WRONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if errors.Is(err, syscall.ENXIO) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+
+	if _, err := syscall.Write(fd, b); err != nil {
+		if errors.Is(err, syscall.EAGAIN) {
+			return fmt.Errorf("fifo %s is full, reader is not keeping up", path)
+		}
+		return err
+	}
+	return nil
+}
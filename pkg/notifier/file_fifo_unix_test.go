@@ -0,0 +1,50 @@
+//go:build !windows
+
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNotifierFIFO(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.fifo")
+	require.NoError(t, syscall.Mkfifo(path, 0600))
+	notifier := &FileNotifier{
+		cfg: &config.FileConfig{Path: path, Format: "text"},
+		now: time.Now,
+	}
+
+	t.Run("does not block without a reader", func(t *testing.T) {
+		done := make(chan error, 1)
+		go func() {
+			done <- notifier.Notify(context.Background(), "nobody is listening")
+		}()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Notify blocked on a FIFO without a reader")
+		}
+	})
+
+	t.Run("delivers to an attached reader", func(t *testing.T) {
+		reader, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		require.NoError(t, notifier.Notify(context.Background(), "hello reader"))
+		line, err := bufio.NewReader(reader).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "hello reader\n", line)
+	})
+}
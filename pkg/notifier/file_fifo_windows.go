@@ -0,0 +1,10 @@
+//go:build windows
+
+package notifier
+
+import "errors"
+
+func writeFIFO(path string, b []byte) error {
+	// not supported (for now?)
+	return errors.New("named pipes are not supported on Windows")
+}
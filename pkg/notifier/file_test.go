@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNotifier(t *testing.T) {
+	fixedNow := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	type testCase struct {
+		name       string
+		fileConfig config.FileConfig
+		want       string
+		wantErr    error
+	}
+	testCases := []testCase{
+		{
+			name:       "happy path - jsonl by default",
+			fileConfig: config.FileConfig{},
+			want:       `{"time":"2024-01-02T03:04:05Z","message":"my notification\nsecond line"}` + "\n",
+		},
+		{
+			name:       "happy path - text escapes newlines",
+			fileConfig: config.FileConfig{Format: "text"},
+			want:       `my notification\nsecond line` + "\n",
+		},
+		{
+			name:       "happy path - template",
+			fileConfig: config.FileConfig{Format: "template", Template: "[{{time}}] {{message}}"},
+			want:       "[2024-01-02T03:04:05Z] my notification\nsecond line\n",
+		},
+		{
+			name:       "sad path - template without template",
+			fileConfig: config.FileConfig{Format: "template"},
+			wantErr:    ErrFileMissingTemplate,
+		},
+		{
+			name:       "sad path - invalid format",
+			fileConfig: config.FileConfig{Format: "xml"},
+			wantErr:    ErrFileInvalidFormat,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notifications.log")
+			tc.fileConfig.Path = path
+			notifier := &FileNotifier{
+				cfg: &tc.fileConfig,
+				now: func() time.Time { return fixedNow },
+			}
+			err := notifier.Notify(context.Background(), "my notification\nsecond line")
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantErr != nil {
+				return
+			}
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(data))
+		})
+	}
+}
+
+func TestFileNotifierAppendsAndRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	notifier := &FileNotifier{
+		cfg: &config.FileConfig{
+			Path:       path,
+			Format:     "text",
+			RotateSize: "10",
+		},
+		now: time.Now,
+	}
+
+	require.NoError(t, notifier.Notify(context.Background(), "one"))
+	require.NoError(t, notifier.Notify(context.Background(), "two"))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(data))
+
+	require.NoError(t, notifier.Notify(context.Background(), "three"))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "three\n", string(data))
+	rotated, err := os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(rotated))
+
+	// only one rotated file is kept
+	require.NoError(t, notifier.Notify(context.Background(), "four"))
+	rotated, err = os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "three\n", string(rotated))
+}
+
+func TestFileNotifierTextKeepsAlignment(t *testing.T) {
+	notifier := &FileNotifier{
+		cfg: &config.FileConfig{Format: "text"},
+		now: time.Now,
+	}
+	line, err := notifier.formatLine("3 commands finished:\n  ok      make build\t12s\r\n  FAILED  make test\t3s\n")
+	require.NoError(t, err)
+	assert.Equal(t, `3 commands finished:\n  ok      make build`+"\t"+`12s\n  FAILED  make test`+"\t"+"3s\n", line)
+}
+
+func TestFileNotifierMissingConfig(t *testing.T) {
+	notifier := &FileNotifier{now: time.Now}
+	assert.Equal(t, ErrFileMissingConfig, notifier.Notify(context.Background(), "my notification"))
+
+	notifier = &FileNotifier{cfg: &config.FileConfig{}, now: time.Now}
+	assert.Equal(t, ErrFileMissingPath, notifier.Notify(context.Background(), "my notification"))
+}
@@ -1,93 +1,712 @@
 package marker
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"regexp"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/lba-studio/n-cli/pkg/capture"
+	"github.com/lba-studio/n-cli/pkg/cgroup"
 	"github.com/lba-studio/n-cli/pkg/formatter"
+	"github.com/lba-studio/n-cli/pkg/history"
 	"github.com/lba-studio/n-cli/pkg/monitor"
 	"github.com/lba-studio/n-cli/pkg/notifier"
+	"github.com/lba-studio/n-cli/pkg/runlog"
+	"github.com/lba-studio/n-cli/pkg/runner"
 )
 
 type NotificationMarker interface {
+	// Start begins heartbeats and stall detection, if enabled. Call it once
+	// the command has been started, and again when each retry starts.
+	Start()
+	// Stopped records why n-cli ended the command early, e.g. "TIMED OUT after 45m0s".
+	// The reason replaces the usual COMPLETE/FAILED status.
+	Stopped(reason string)
+	// Retry records the exit code and duration of the attempt that just
+	// finished, and switches over to next, the command for the following attempt.
+	Retry(exitCode int, elapsed time.Duration, next *exec.Cmd)
+	// ScanLine checks a line of output against Options.Patterns and sends a
+	// notification when it matches.
+	ScanLine(line string)
 	Done()
 }
 
 type NotificationMarkerImpl struct {
 	StartedFrom time.Time
 	Command     *exec.Cmd
+	Options     Options
+
+	stop    chan struct{}
+	running sync.WaitGroup
+	// commandMu guards Command and pid against Retry, since heartbeats, stall
+	// detection and ScanLine keep running in between attempts.
+	commandMu          sync.Mutex
+	pid                int
+	stopReason         string
+	attempts           []Attempt
+	attemptStartedFrom time.Time
+	patternMu          sync.Mutex
+	patternState       map[int]*patternState
+	patternMatched     bool
+	// patternSends has its own WaitGroup, since ScanLine can still be called
+	// while Done waits, e.g. from a pty that outlives its drain timeout.
+	// Matches are ignored once patternsStopped is set.
+	patternSends    sync.WaitGroup
+	patternsStopped bool
+}
+
+type patternState struct {
+	notifiedAt time.Time
+	skipped    int
 }
 
-func NewNotificationMarker(cmd *exec.Cmd) NotificationMarker {
+// Attempt is one run of the command when retrying.
+type Attempt struct {
+	ExitCode   int
+	Elapsed    time.Duration
+	StopReason string
+}
+
+type Options struct {
+	// DisplayCommand is shown in the notification instead of the command's
+	// argv, e.g. the original command line when running through a shell.
+	DisplayCommand string
+	// OutputTail holds the last lines of the command's output. They are added
+	// to the notification when the command fails.
+	OutputTail *capture.LineBuffer
+	// OutputTailSource describes what OutputTail captured, e.g. "output" or "stderr".
+	OutputTailSource string
+	// MinDuration suppresses notifications for commands that finish sooner.
+	MinDuration time.Duration
+	// NotifyOn picks which outcomes are notified about. Defaults to NotifyOnAlways.
+	NotifyOn NotifyOn
+	// SuccessExitCodes lists the exit codes that count as success. Defaults to 0 only.
+	SuccessExitCodes []int
+	// Heartbeat sends a "still running" notification at this interval.
+	Heartbeat time.Duration
+	// StallAfter sends a notification when Activity has not seen any output
+	// for this long. Requires Activity.
+	StallAfter time.Duration
+	Activity   *capture.Activity
+	// Patterns trigger a notification whenever a line of output matches one
+	// of them.
+	Patterns []*regexp.Regexp
+	// PatternDebounce is the minimum time between two notifications for the
+	// same pattern; matches in between are counted but not sent.
+	PatternDebounce time.Duration
+	// OnPatternMatch is called once, after the first match has been notified.
+	OnPatternMatch func(pattern string)
+	// Sampler samples the command's process tree while it runs; a summary is
+	// added to the notification.
+	Sampler *monitor.Sampler
+	// Cgroup is the cgroup the command runs in; its accounting is added to
+	// the notification.
+	Cgroup *cgroup.Cgroup
+	// Baseline is the usual duration of the command. Successful runs are
+	// compared to it in the notification.
+	Baseline *history.Baseline
+	// NotifyIfSlower only sends the final notification when the run took at
+	// least this many times the Baseline, e.g. 1.5. Without a Baseline, no
+	// notification is sent.
+	NotifyIfSlower float64
+	// ETA prints when the command is expected to finish, based on the
+	// Baseline, once it starts.
+	ETA bool
+	// ETANotifyAt sends an "about N% done" notification once this fraction of
+	// the Baseline has passed, e.g. 0.8. Requires a Baseline.
+	ETANotifyAt float64
+	// Log holds the complete output of the command. Its path and URL are added
+	// to the notification if it is kept.
+	Log *runlog.Log
+	// MaxAttempts is how many times the command may run in total. Set it when
+	// retrying, so that the notification can tell which attempt it was.
+	MaxAttempts int
+}
+
+type NotifyOn string
+
+const (
+	NotifyOnAlways  NotifyOn = "always"
+	NotifyOnSuccess NotifyOn = "success"
+	NotifyOnFailure NotifyOn = "failure"
+)
+
+var ErrInvalidNotifyOn = errors.New("invalid notify-on value (expected always, success or failure)")
+
+func ParseNotifyOn(s string) (NotifyOn, error) {
+	switch on := NotifyOn(strings.ToLower(s)); on {
+	case "":
+		return NotifyOnAlways, nil
+	case NotifyOnAlways, NotifyOnSuccess, NotifyOnFailure:
+		return on, nil
+	default:
+		return "", ErrInvalidNotifyOn
+	}
+}
+
+// notify and notifyProgress are swapped out in tests.
+var (
+	notify = notifier.Notify
+	// notifyProgress reports to stderr, since the command may still be
+	// writing to stdout.
+	notifyProgress = func(msg string) error {
+		return notifier.NotifyTo(msg, os.Stderr)
+	}
+)
+
+// maxTailLineLength keeps a single runaway line from crowding out the rest of
+// the tail; channel limits are enforced separately by the notifier.
+const maxTailLineLength = 300
+
+func NewNotificationMarker(cmd *exec.Cmd, opts Options) NotificationMarker {
 	return &NotificationMarkerImpl{
 		StartedFrom: time.Now(),
 		Command:     cmd,
+		Options:     opts,
 	}
 }
 
 type printedMarkerInfo struct {
-	exitCode    int
-	elapsed     string
-	cpuTime     string
-	memoryUsage int64
+	exitCode   int
+	succeeded  bool
+	stopReason string
+	attempts   []Attempt
+	elapsed    string
+	// lastAttempt is the duration of the last attempt, which is compared to
+	// Options.Baseline.
+	lastAttempt time.Duration
+	usage       *monitor.Usage
+	samples     []monitor.Sample
+	cgroup      *cgroup.Stats
 }
 
 func (m *NotificationMarkerImpl) formatMessage(info printedMarkerInfo) string {
 	status := "COMPLETE"
-	if info.exitCode > 0 {
+	if len(info.attempts) > 0 {
+		status = m.formatAttemptStatus(info)
+	} else if info.stopReason != "" {
+		status = info.stopReason
+	} else if !info.succeeded {
 		status = "FAILED"
+	} else if info.exitCode != 0 {
+		status = fmt.Sprintf("COMPLETE (exit code %d)", info.exitCode)
 	}
-	prettyCommand := strings.Join(m.Command.Args, " ")
-
 	infoStrings := []string{
-		fmt.Sprintf("Command `%s` %s.", prettyCommand, status),
+		fmt.Sprintf("Command `%s` %s.", m.prettyCommand(), status),
 		fmt.Sprintf("Elapsed: %s", info.elapsed),
 	}
-	if !monitor.IsWindows() {
-		infoStrings = append(infoStrings,
-			fmt.Sprintf("CPU Time: %s", info.cpuTime),
-			fmt.Sprintf("Memory Usage: %s", formatter.PrettyPrintInt64(info.memoryUsage)),
-		)
+	if info.succeeded && m.Options.Baseline != nil {
+		infoStrings = append(infoStrings, formatComparison(m.Options.Baseline, info.lastAttempt))
+	}
+	if info.usage != nil {
+		infoStrings = append(infoStrings, formatUsage(info.usage)...)
+	}
+	if len(info.samples) > 0 {
+		infoStrings = append(infoStrings, formatSampleSummary(monitor.Summarize(info.samples))...)
+	}
+	if info.cgroup != nil {
+		infoStrings = append(infoStrings, m.formatCgroupStats(info.cgroup)...)
+	}
+
+	if len(info.attempts) > 0 {
+		infoStrings = append(infoStrings, "Attempts:")
+		for i, attempt := range info.attempts {
+			result := fmt.Sprintf("exit code %d", attempt.ExitCode)
+			if attempt.StopReason != "" {
+				result = attempt.StopReason
+			}
+			infoStrings = append(infoStrings, fmt.Sprintf("  #%d: %s in %s", i+1, result, attempt.Elapsed.Round(time.Millisecond)))
+		}
+	}
+
+	if log := m.Options.Log; log != nil && log.Keep(info.succeeded) {
+		infoStrings = append(infoStrings, fmt.Sprintf("Log: %s", log.Path), fmt.Sprintf("Log URL: %s", log.URL()))
+	}
+
+	if !info.succeeded && m.Options.OutputTail != nil {
+		if lines := m.Options.OutputTail.Lines(); len(lines) > 0 {
+			source := m.Options.OutputTailSource
+			if source == "" {
+				source = "output"
+			}
+			infoStrings = append(infoStrings, fmt.Sprintf("Last %d lines of %s:", len(lines), source))
+			for _, line := range lines {
+				infoStrings = append(infoStrings, formatter.TruncateLine(line, maxTailLineLength))
+			}
+		}
+	}
+
+	return strings.Join(infoStrings, "\n")
+}
+
+// formatComparison compares a run to the usual duration of the command, e.g.
+// "Usual: 4m12s (p50 of 12 runs), this run: 9m3s — 2.1× slower".
+func formatComparison(b *history.Baseline, elapsed time.Duration) string {
+	ratio := b.Ratio(elapsed)
+	verdict := "about as usual"
+	if ratio >= 1.1 {
+		verdict = fmt.Sprintf("%.1f× slower", ratio)
+	} else if ratio > 0 && ratio <= 0.9 {
+		verdict = fmt.Sprintf("%.1f× faster", 1/ratio)
+	}
+	return fmt.Sprintf("Usual: %s (p50 of %d runs), this run: %s — %s", roundDuration(b.P50), b.Runs, roundDuration(elapsed), verdict)
+}
+
+// roundDuration drops the precision that doesn't matter at a glance.
+func roundDuration(d time.Duration) time.Duration {
+	if d >= 10*time.Second {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Millisecond)
+}
+
+func (m *NotificationMarkerImpl) samples() []monitor.Sample {
+	if m.Options.Sampler == nil {
+		return nil
+	}
+	return m.Options.Sampler.Samples()
+}
+
+func (m *NotificationMarkerImpl) cgroupStats() *cgroup.Stats {
+	if m.Options.Cgroup == nil {
+		return nil
+	}
+	stats, err := m.Options.Cgroup.Stats()
+	if err != nil {
+		fmt.Printf("Cannot get cgroup stats: %s\n", err.Error())
+		return nil
+	}
+	return stats
+}
+
+func formatUsage(u *monitor.Usage) []string {
+	return []string{
+		fmt.Sprintf("CPU Time: %s (user %s, sys %s)", u.CPUTime(), u.UserTime, u.SystemTime),
+		fmt.Sprintf("Memory Usage: %s (peak RSS)", formatter.PrettyPrintBytes(u.MaxRSS)),
+		fmt.Sprintf("Block I/O: %s blocks in, %s blocks out", formatter.PrettyPrintInt64(u.BlockInputOps), formatter.PrettyPrintInt64(u.BlockOutputOps)),
+		fmt.Sprintf("Context Switches: %s voluntary, %s involuntary", formatter.PrettyPrintInt64(u.VoluntaryCtxSwitches), formatter.PrettyPrintInt64(u.InvoluntaryCtxSwitches)),
+		fmt.Sprintf("Major Page Faults: %s", formatter.PrettyPrintInt64(u.MajorPageFaults)),
+	}
+}
+
+func formatSampleSummary(summary monitor.SampleSummary) []string {
+	return []string{
+		fmt.Sprintf("Sampled CPU: avg %.0f%%, peak %.0f%%", summary.AvgCPU, summary.PeakCPU),
+		fmt.Sprintf("Peak Tree RSS: %s", formatter.PrettyPrintBytes(summary.PeakRSS)),
+		fmt.Sprintf("Total I/O: %s read, %s written", formatter.PrettyPrintBytes(summary.ReadBytes), formatter.PrettyPrintBytes(summary.WriteBytes)),
+	}
+}
+
+func (m *NotificationMarkerImpl) formatCgroupStats(s *cgroup.Stats) []string {
+	cpu := fmt.Sprintf("Cgroup CPU Time: %s (user %s, sys %s)", s.CPUTime(), s.CPUUser, s.CPUSystem)
+	if s.ThrottledPeriods > 0 {
+		cpu += fmt.Sprintf(", throttled %d times for %s", s.ThrottledPeriods, s.ThrottledTime)
+	}
+	infoStrings := []string{cpu}
+	if s.MemoryPeak > 0 {
+		memory := fmt.Sprintf("Cgroup Memory Peak: %s", formatter.PrettyPrintBytes(s.MemoryPeak))
+		if s.MemoryMax > 0 {
+			memory += fmt.Sprintf(" (limit %s)", formatter.PrettyPrintBytes(s.MemoryMax))
+		}
+		infoStrings = append(infoStrings, memory)
+	}
+	infoStrings = append(infoStrings, fmt.Sprintf("Cgroup I/O: %s read, %s written", formatter.PrettyPrintBytes(s.ReadBytes), formatter.PrettyPrintBytes(s.WriteBytes)))
+	if s.OOMKills > 0 {
+		infoStrings = append(infoStrings, fmt.Sprintf("OOM Kills: %d", s.OOMKills))
+	}
+	if s.Processes > 0 {
+		infoStrings = append(infoStrings, fmt.Sprintf("Still Running: %d processes in %s", s.Processes, m.Options.Cgroup.Path))
+	}
+	return infoStrings
+}
+
+func (m *NotificationMarkerImpl) formatAttemptStatus(info printedMarkerInfo) string {
+	attempt := len(info.attempts)
+	maxAttempts := max(m.Options.MaxAttempts, attempt)
+	switch {
+	case info.stopReason != "":
+		return fmt.Sprintf("%s on attempt %d/%d, total %s", info.stopReason, attempt, maxAttempts, info.elapsed)
+	case info.succeeded:
+		return fmt.Sprintf("COMPLETE on attempt %d/%d, total %s", attempt, maxAttempts, info.elapsed)
+	default:
+		return fmt.Sprintf("FAILED on attempt %d/%d, total %s", attempt, maxAttempts, info.elapsed)
+	}
+}
+
+func (m *NotificationMarkerImpl) prettyCommand() string {
+	if m.Options.DisplayCommand != "" {
+		return m.Options.DisplayCommand
+	}
+	m.commandMu.Lock()
+	defer m.commandMu.Unlock()
+	return strings.Join(m.Command.Args, " ")
+}
+
+func (m *NotificationMarkerImpl) Stopped(reason string) {
+	m.stopReason = reason
+}
+
+func (m *NotificationMarkerImpl) Retry(exitCode int, elapsed time.Duration, next *exec.Cmd) {
+	m.attempts = append(m.attempts, Attempt{
+		ExitCode: exitCode,
+		Elapsed:  elapsed,
+	})
+	m.commandMu.Lock()
+	defer m.commandMu.Unlock()
+	m.Command = next
+	m.pid = 0
+}
+
+func (m *NotificationMarkerImpl) attemptStart() time.Time {
+	if m.attemptStartedFrom.IsZero() {
+		return m.StartedFrom
+	}
+	return m.attemptStartedFrom
+}
+
+func (m *NotificationMarkerImpl) Start() {
+	if len(m.attempts) > 0 {
+		m.attemptStartedFrom = time.Now()
+	}
+	m.commandMu.Lock()
+	if m.Command.Process != nil {
+		m.pid = m.Command.Process.Pid
+	}
+	pid := m.pid
+	m.commandMu.Unlock()
+	if m.Options.Sampler != nil && pid != 0 {
+		if err := m.Options.Sampler.Start(pid); err != nil {
+			fmt.Fprintf(os.Stderr, "WARN: Cannot sample resource usage: %s\n", err.Error())
+		}
+	}
+	if m.stop != nil {
+		return
+	}
+	m.stop = make(chan struct{})
+	if m.Options.ETA {
+		fmt.Fprintf(os.Stderr, "n-cli run: %s\n", m.formatETA(time.Now()))
+	}
+	if m.Options.ETANotifyAt > 0 && m.Options.Baseline != nil {
+		m.running.Add(1)
+		go m.runETANotification()
+	}
+	if m.Options.Heartbeat > 0 {
+		m.running.Add(1)
+		go m.runHeartbeat()
 	}
+	if m.Options.StallAfter > 0 && m.Options.Activity != nil {
+		m.running.Add(1)
+		go m.runStallDetection()
+	}
+}
+
+func (m *NotificationMarkerImpl) stopBackground() {
+	if m.Options.Sampler != nil {
+		m.Options.Sampler.Stop()
+	}
+	if m.stop != nil {
+		close(m.stop)
+	}
+	m.running.Wait()
+	m.stop = nil
+}
+
+func (m *NotificationMarkerImpl) runHeartbeat() {
+	defer m.running.Done()
+	ticker := time.NewTicker(m.Options.Heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.sendProgress(m.formatHeartbeatMessage(time.Since(m.StartedFrom), m.currentRSS()))
+		}
+	}
+}
+
+func (m *NotificationMarkerImpl) runETANotification() {
+	defer m.running.Done()
+	at := time.Duration(float64(m.Options.Baseline.P50) * m.Options.ETANotifyAt)
+	timer := time.NewTimer(time.Until(m.StartedFrom.Add(at)))
+	defer timer.Stop()
+	select {
+	case <-m.stop:
+	case <-timer.C:
+		m.sendProgress(m.formatETANotification(time.Now()))
+	}
+}
+
+// formatETA tells when the command is expected to finish, if it started now.
+func (m *NotificationMarkerImpl) formatETA(now time.Time) string {
+	b := m.Options.Baseline
+	if b == nil {
+		return fmt.Sprintf("no ETA yet, it takes %d successful runs of this command in this directory", history.MinRuns)
+	}
+	return fmt.Sprintf("usually takes %s (p50 of %d runs), expected to finish at %s", roundDuration(b.P50), b.Runs, formatClock(now.Add(b.P50), now))
+}
+
+func (m *NotificationMarkerImpl) formatETANotification(now time.Time) string {
+	b := m.Options.Baseline
+	finish := m.StartedFrom.Add(b.P50)
+	return strings.Join([]string{
+		fmt.Sprintf("Command `%s` is about %.0f%% done.", m.prettyCommand(), m.Options.ETANotifyAt*100),
+		fmt.Sprintf("Usually takes: %s (p50 of %d runs)", roundDuration(b.P50), b.Runs),
+		fmt.Sprintf("Expected to finish at %s (in %s)", formatClock(finish, now), roundDuration(finish.Sub(now))),
+		fmt.Sprintf("Elapsed: %s", roundDuration(now.Sub(m.StartedFrom))),
+	}, "\n")
+}
+
+// formatClock formats t as a time of day, with the weekday if it is not today.
+func formatClock(t, now time.Time) string {
+	if t.YearDay() != now.YearDay() || t.Year() != now.Year() {
+		return t.Format("Mon 15:04")
+	}
+	return t.Format("15:04")
+}
+
+// stallCheckInterval bounds how late a stall notification can be.
+var stallCheckInterval = time.Second
+
+func (m *NotificationMarkerImpl) runStallDetection() {
+	defer m.running.Done()
+	ticker := time.NewTicker(stallCheckInterval)
+	defer ticker.Stop()
+	// only notify once per stall; any new output re-arms the check
+	var notifiedFor time.Time
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			last := m.Options.Activity.Last()
+			if time.Since(last) < m.Options.StallAfter || last.Equal(notifiedFor) {
+				continue
+			}
+			notifiedFor = last
+			m.sendProgress(m.formatStallMessage(time.Since(last), time.Since(m.StartedFrom), m.currentRSS()))
+		}
+	}
+}
+
+// currentRSS returns -1 when the RSS of the command cannot be sampled.
+func (m *NotificationMarkerImpl) currentRSS() int64 {
+	m.commandMu.Lock()
+	pid := m.pid
+	m.commandMu.Unlock()
+	if pid == 0 {
+		return -1
+	}
+	rss, err := monitor.GetRSS(pid)
+	if err != nil {
+		return -1
+	}
+	return rss
+}
+
+func formatRSS(rss int64) string {
+	if rss < 0 {
+		return "unknown"
+	}
+	return formatter.PrettyPrintBytes(rss)
+}
+
+func (m *NotificationMarkerImpl) formatHeartbeatMessage(elapsed time.Duration, rss int64) string {
+	return strings.Join([]string{
+		fmt.Sprintf("Command `%s` is still running.", m.prettyCommand()),
+		fmt.Sprintf("Elapsed: %s", elapsed.Round(time.Second)),
+		fmt.Sprintf("Current RSS: %s", formatRSS(rss)),
+	}, "\n")
+}
+
+func (m *NotificationMarkerImpl) formatStallMessage(silentFor, elapsed time.Duration, rss int64) string {
+	return strings.Join([]string{
+		fmt.Sprintf("Command `%s` may be stuck: no output for %s.", m.prettyCommand(), silentFor.Round(time.Second)),
+		fmt.Sprintf("Elapsed: %s", elapsed.Round(time.Second)),
+		fmt.Sprintf("Current RSS: %s", formatRSS(rss)),
+	}, "\n")
+}
 
+func (m *NotificationMarkerImpl) ScanLine(line string) {
+	for i, pattern := range m.Options.Patterns {
+		if !pattern.MatchString(line) {
+			continue
+		}
+		msg, ok := m.debouncePattern(i, line)
+		if !ok {
+			continue
+		}
+		m.patternMu.Lock()
+		if m.patternsStopped {
+			m.patternMu.Unlock()
+			return
+		}
+		first := !m.patternMatched
+		m.patternMatched = true
+		m.patternSends.Add(1)
+		m.patternMu.Unlock()
+		// don't hold up the command's output while the notification is sent
+		go func(pattern string) {
+			defer m.patternSends.Done()
+			m.sendProgress(msg)
+			if first && m.Options.OnPatternMatch != nil {
+				m.Options.OnPatternMatch(pattern)
+			}
+		}(pattern.String())
+	}
+}
+
+// stopPatterns waits for the pattern notifications in flight, and ignores
+// any later matches.
+func (m *NotificationMarkerImpl) stopPatterns() {
+	m.patternMu.Lock()
+	m.patternsStopped = true
+	m.patternMu.Unlock()
+	m.patternSends.Wait()
+}
+
+// debouncePattern returns the notification for a match of pattern i, unless
+// one was already sent within PatternDebounce.
+func (m *NotificationMarkerImpl) debouncePattern(i int, line string) (string, bool) {
+	m.patternMu.Lock()
+	defer m.patternMu.Unlock()
+	if m.patternState == nil {
+		m.patternState = map[int]*patternState{}
+	}
+	state, ok := m.patternState[i]
+	if !ok {
+		state = &patternState{}
+		m.patternState[i] = state
+	}
+	now := time.Now()
+	if !state.notifiedAt.IsZero() && now.Sub(state.notifiedAt) < m.Options.PatternDebounce {
+		state.skipped++
+		return "", false
+	}
+	msg := m.formatPatternMessage(m.Options.Patterns[i].String(), line, state.skipped, now.Sub(m.StartedFrom))
+	state.notifiedAt = now
+	state.skipped = 0
+	return msg, true
+}
+
+func (m *NotificationMarkerImpl) formatPatternMessage(pattern, line string, skipped int, elapsed time.Duration) string {
+	infoStrings := []string{
+		fmt.Sprintf("Command `%s` printed a line matching `%s`:", m.prettyCommand(), pattern),
+		formatter.TruncateLine(line, maxTailLineLength),
+	}
+	if skipped == 1 {
+		infoStrings = append(infoStrings, "(1 more match since the last notification)")
+	} else if skipped > 1 {
+		infoStrings = append(infoStrings, fmt.Sprintf("(%d more matches since the last notification)", skipped))
+	}
+	infoStrings = append(infoStrings, fmt.Sprintf("Elapsed: %s", elapsed.Round(time.Second)))
 	return strings.Join(infoStrings, "\n")
 }
 
+func (m *NotificationMarkerImpl) sendProgress(msg string) {
+	if err := notifyProgress(msg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encountered when sending notification: %s\n", err.Error())
+	}
+}
+
+func (m *NotificationMarkerImpl) isSuccess(exitCode int) bool {
+	if len(m.Options.SuccessExitCodes) == 0 {
+		return exitCode == 0
+	}
+	for _, code := range m.Options.SuccessExitCodes {
+		if code == exitCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *NotificationMarkerImpl) shouldNotify(succeeded bool, elapsed time.Duration) bool {
+	if elapsed < m.Options.MinDuration {
+		return false
+	}
+	switch m.Options.NotifyOn {
+	case NotifyOnSuccess:
+		return succeeded
+	case NotifyOnFailure:
+		return !succeeded
+	default:
+		return true
+	}
+}
+
+// slowEnough reports whether a run is slow enough for NotifyIfSlower.
+func (m *NotificationMarkerImpl) slowEnough(elapsed time.Duration) bool {
+	if m.Options.NotifyIfSlower <= 0 {
+		return true
+	}
+	return m.Options.Baseline != nil && m.Options.Baseline.Ratio(elapsed) >= m.Options.NotifyIfSlower
+}
+
 func (m *NotificationMarkerImpl) Done() {
+	m.stopBackground()
+	m.stopPatterns()
 	defer func() {
 		if r := recover(); r != nil {
 			fmt.Println("Panic encountered while processing process information. Skipping analytics.", r)
 		}
 	}()
 	elapsed := time.Since(m.StartedFrom)
-	cpuTimeNano, err := monitor.GetCPU()
-	if err != nil && err != monitor.ErrIsWindows {
-		fmt.Printf("Cannot get cpuTimeNano: %s\n", err.Error())
+	exitCode := m.Command.ProcessState.ExitCode()
+	if m.Command.ProcessState == nil && m.stopReason == "" {
+		// callers that know why pass it through Stopped
+		m.stopReason = "FAILED to start"
+	} else if exitCode < 0 && m.stopReason == "" {
+		// a signal that n-cli did not send, e.g. a crash or the OOM killer
+		sig, ok := runner.TerminatingSignal(m.Command.ProcessState)
+		if !ok {
+			return
+		}
+		m.stopReason = "KILLED by " + runner.SignalName(syscall.Signal(sig))
+	}
+	succeeded := m.stopReason == "" && m.isSuccess(exitCode)
+	lastAttempt := time.Since(m.attemptStart())
+	if !m.shouldNotify(succeeded, elapsed) || !m.slowEnough(lastAttempt) {
 		return
 	}
-	cpuTime := time.Duration(time.Duration(cpuTimeNano) * time.Nanosecond)
 
-	memoryUsage, err := monitor.GetMemoryFromCmd(m.Command)
-	if err != nil && err != monitor.ErrIsWindows {
-		fmt.Printf("Cannot get memoryUsage: %s\n", err.Error())
-		return
+	var usage *monitor.Usage
+	if m.Command.ProcessState != nil {
+		var err error
+		usage, err = monitor.GetUsage(m.Command.ProcessState)
+		if err != nil && err != monitor.ErrIsWindows {
+			fmt.Printf("Cannot get resource usage: %s\n", err.Error())
+		}
 	}
 
-	exitCode := m.Command.ProcessState.ExitCode()
-	if exitCode < 0 {
-		return
+	var attempts []Attempt
+	if len(m.attempts) > 0 {
+		attempts = append(m.attempts, Attempt{
+			ExitCode:   exitCode,
+			Elapsed:    lastAttempt,
+			StopReason: m.stopReason,
+		})
 	}
+
 	msg := m.formatMessage(printedMarkerInfo{
-		memoryUsage: memoryUsage,
-		cpuTime:     cpuTime.String(),
+		usage:       usage,
+		samples:     m.samples(),
+		cgroup:      m.cgroupStats(),
 		elapsed:     elapsed.String(),
+		lastAttempt: lastAttempt,
 		exitCode:    exitCode,
+		succeeded:   succeeded,
+		stopReason:  m.stopReason,
+		attempts:    attempts,
 	})
 
-	err = notifier.Notify(msg)
-	if err != nil {
+	if err := notify(msg); err != nil {
 		fmt.Printf("Error encountered when sending notification: %s\n", err.Error())
 	}
 }
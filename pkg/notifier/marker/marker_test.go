@@ -0,0 +1,452 @@
+package marker
+
+import (
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/capture"
+	"github.com/lba-studio/n-cli/pkg/cgroup"
+	"github.com/lba-studio/n-cli/pkg/history"
+	"github.com/lba-studio/n-cli/pkg/monitor"
+	"github.com/lba-studio/n-cli/pkg/runlog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMessageOutputTail(t *testing.T) {
+	tail := capture.NewLineBuffer(2)
+	io.WriteString(tail.Writer(), "line 1\nline 2\n"+strings.Repeat("x", 400)+"\n")
+
+	m := &NotificationMarkerImpl{
+		Command: exec.Command("make", "test"),
+		Options: Options{
+			OutputTail:       tail,
+			OutputTailSource: "stderr",
+		},
+	}
+
+	t.Run("failed commands include the tail", func(t *testing.T) {
+		msg := m.formatMessage(printedMarkerInfo{exitCode: 2, succeeded: false, elapsed: "1s"})
+		assert.Contains(t, msg, "Command `make test` FAILED.")
+		assert.Contains(t, msg, "Last 2 lines of stderr:\nline 2\n"+strings.Repeat("x", maxTailLineLength-1)+"…")
+		assert.NotContains(t, msg, "line 1")
+	})
+
+	t.Run("successful commands do not", func(t *testing.T) {
+		msg := m.formatMessage(printedMarkerInfo{exitCode: 0, succeeded: true, elapsed: "1s"})
+		assert.Contains(t, msg, "Command `make test` COMPLETE.")
+		assert.NotContains(t, msg, "Last 2 lines")
+	})
+}
+
+func TestFormatMessageDisplayCommand(t *testing.T) {
+	m := &NotificationMarkerImpl{
+		Command: exec.Command("/bin/sh", "-c", "make && make test"),
+		Options: Options{DisplayCommand: "make && make test"},
+	}
+	msg := m.formatMessage(printedMarkerInfo{exitCode: 0, succeeded: true, elapsed: "1s"})
+	assert.True(t, strings.HasPrefix(msg, "Command `make && make test` COMPLETE."))
+}
+
+func TestFormatMessageAllowedExitCode(t *testing.T) {
+	m := &NotificationMarkerImpl{Command: exec.Command("grep", "needle", "haystack")}
+	msg := m.formatMessage(printedMarkerInfo{exitCode: 1, succeeded: true, elapsed: "1s"})
+	assert.True(t, strings.HasPrefix(msg, "Command `grep needle haystack` COMPLETE (exit code 1)."))
+}
+
+func TestFormatMessageStopReason(t *testing.T) {
+	m := &NotificationMarkerImpl{Command: exec.Command("make", "integration-test")}
+	msg := m.formatMessage(printedMarkerInfo{exitCode: 124, succeeded: false, stopReason: "TIMED OUT after 45m0s", elapsed: "45m"})
+	assert.True(t, strings.HasPrefix(msg, "Command `make integration-test` TIMED OUT after 45m0s."))
+}
+
+func TestFormatMessageAttempts(t *testing.T) {
+	m := &NotificationMarkerImpl{
+		StartedFrom: time.Now(),
+		Command:     exec.Command("make", "test"),
+		Options:     Options{MaxAttempts: 3},
+	}
+	m.Retry(1, 4*time.Minute, exec.Command("make", "test"))
+	m.Retry(137, 5*time.Minute, exec.Command("make", "test"))
+	attempts := append(m.attempts, Attempt{ExitCode: 0, Elapsed: 5 * time.Minute})
+
+	t.Run("success lists every attempt", func(t *testing.T) {
+		msg := m.formatMessage(printedMarkerInfo{exitCode: 0, succeeded: true, elapsed: "14m0s", attempts: attempts})
+		assert.True(t, strings.HasPrefix(msg, "Command `make test` COMPLETE on attempt 3/3, total 14m0s."))
+		assert.Contains(t, msg, "Attempts:\n  #1: exit code 1 in 4m0s\n  #2: exit code 137 in 5m0s\n  #3: exit code 0 in 5m0s")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		attempts := append(m.attempts[:2:2], Attempt{ExitCode: 1, Elapsed: time.Minute})
+		msg := m.formatMessage(printedMarkerInfo{exitCode: 1, succeeded: false, elapsed: "10m0s", attempts: attempts})
+		assert.True(t, strings.HasPrefix(msg, "Command `make test` FAILED on attempt 3/3, total 10m0s."))
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		attempts := append(m.attempts[:2:2], Attempt{ExitCode: -1, Elapsed: time.Minute, StopReason: "TIMED OUT after 1m0s"})
+		msg := m.formatMessage(printedMarkerInfo{exitCode: -1, stopReason: "TIMED OUT after 1m0s", elapsed: "10m0s", attempts: attempts})
+		assert.True(t, strings.HasPrefix(msg, "Command `make test` TIMED OUT after 1m0s on attempt 3/3, total 10m0s."))
+		assert.Contains(t, msg, "  #3: TIMED OUT after 1m0s in 1m0s")
+	})
+
+	t.Run("single attempt keeps the usual status", func(t *testing.T) {
+		msg := m.formatMessage(printedMarkerInfo{exitCode: 0, succeeded: true, elapsed: "1s"})
+		assert.True(t, strings.HasPrefix(msg, "Command `make test` COMPLETE."))
+		assert.NotContains(t, msg, "Attempts:")
+	})
+}
+
+func TestFormatMessageUsage(t *testing.T) {
+	m := &NotificationMarkerImpl{Command: exec.Command("make", "build")}
+	msg := m.formatMessage(printedMarkerInfo{exitCode: 0, succeeded: true, elapsed: "1m0s", usage: &monitor.Usage{
+		UserTime:               50 * time.Second,
+		SystemTime:             5 * time.Second,
+		MaxRSS:                 1536 * 1024 * 1024,
+		BlockInputOps:          12,
+		BlockOutputOps:         3400,
+		VoluntaryCtxSwitches:   12345,
+		InvoluntaryCtxSwitches: 67,
+		MajorPageFaults:        2,
+	}})
+	assert.Equal(t, strings.Join([]string{
+		"Command `make build` COMPLETE.",
+		"Elapsed: 1m0s",
+		"CPU Time: 55s (user 50s, sys 5s)",
+		"Memory Usage: 1.5 GiB (peak RSS)",
+		"Block I/O: 12 blocks in, 3,400 blocks out",
+		"Context Switches: 12,345 voluntary, 67 involuntary",
+		"Major Page Faults: 2",
+	}, "\n"), msg)
+}
+
+func TestFormatMessageSamples(t *testing.T) {
+	m := &NotificationMarkerImpl{Command: exec.Command("make", "-j8")}
+	now := time.Now()
+	msg := m.formatMessage(printedMarkerInfo{exitCode: 0, succeeded: true, elapsed: "2s", samples: []monitor.Sample{
+		{Time: now, CPUPercent: 100, RSS: 512 * 1024 * 1024},
+		{Time: now.Add(time.Second), CPUPercent: 390, RSS: 2 * 1024 * 1024 * 1024, ReadBytes: 1024, WriteBytes: 300 * 1024 * 1024},
+	}})
+	assert.Contains(t, msg, "Sampled CPU: avg 245%, peak 390%\nPeak Tree RSS: 2.0 GiB\nTotal I/O: 1.0 KiB read, 300.0 MiB written")
+}
+
+func TestFormatMessageCgroup(t *testing.T) {
+	m := &NotificationMarkerImpl{Command: exec.Command("make", "-j8")}
+	m.Options.Cgroup = &cgroup.Cgroup{Path: "/sys/fs/cgroup/n-cli.slice/run-1.scope"}
+	msg := m.formatMessage(printedMarkerInfo{exitCode: 137, elapsed: "2s", cgroup: &cgroup.Stats{
+		CPUUser:          3 * time.Second,
+		CPUSystem:        time.Second,
+		ThrottledPeriods: 12,
+		ThrottledTime:    500 * time.Millisecond,
+		MemoryPeak:       1024 * 1024 * 1024,
+		MemoryMax:        1024 * 1024 * 1024,
+		OOMKills:         1,
+		Processes:        2,
+	}})
+	assert.Contains(t, msg, "Cgroup CPU Time: 4s (user 3s, sys 1s), throttled 12 times for 500ms\nCgroup Memory Peak: 1.0 GiB (limit 1.0 GiB)\nCgroup I/O: 0 B read, 0 B written\nOOM Kills: 1\nStill Running: 2 processes in /sys/fs/cgroup/n-cli.slice/run-1.scope")
+}
+
+func TestFormatMessageBaseline(t *testing.T) {
+	m := &NotificationMarkerImpl{Command: exec.Command("make", "build")}
+	m.Options.Baseline = &history.Baseline{P50: 4*time.Minute + 12*time.Second, Runs: 12}
+	testCases := []struct {
+		lastAttempt time.Duration
+		expected    string
+	}{
+		{9*time.Minute + 3*time.Second + 400*time.Millisecond, "Usual: 4m12s (p50 of 12 runs), this run: 9m3s — 2.2× slower"},
+		{4 * time.Minute, "Usual: 4m12s (p50 of 12 runs), this run: 4m0s — about as usual"},
+		{2500 * time.Millisecond, "Usual: 4m12s (p50 of 12 runs), this run: 2.5s — 100.8× faster"},
+	}
+	for _, tc := range testCases {
+		msg := m.formatMessage(printedMarkerInfo{succeeded: true, elapsed: "1s", lastAttempt: tc.lastAttempt})
+		assert.Contains(t, msg, "Elapsed: 1s\n"+tc.expected)
+	}
+
+	// failed runs end early for all sorts of reasons, so they aren't compared
+	msg := m.formatMessage(printedMarkerInfo{exitCode: 1, elapsed: "1s", lastAttempt: time.Second})
+	assert.NotContains(t, msg, "Usual:")
+}
+
+func TestSlowEnough(t *testing.T) {
+	m := &NotificationMarkerImpl{}
+	assert.True(t, m.slowEnough(time.Second))
+
+	m.Options.NotifyIfSlower = 1.5
+	assert.False(t, m.slowEnough(time.Hour), "no baseline yet")
+
+	m.Options.Baseline = &history.Baseline{P50: 4 * time.Minute, Runs: 3}
+	assert.False(t, m.slowEnough(5*time.Minute))
+	assert.True(t, m.slowEnough(6*time.Minute))
+}
+
+func TestFormatETA(t *testing.T) {
+	now := time.Date(2026, 3, 4, 15, 38, 0, 0, time.Local)
+	m := &NotificationMarkerImpl{Command: exec.Command("make", "build"), StartedFrom: now.Add(-3*time.Minute - 20*time.Second)}
+	assert.Equal(t, "no ETA yet, it takes 3 successful runs of this command in this directory", m.formatETA(now))
+
+	m.Options.Baseline = &history.Baseline{P50: 4*time.Minute + 12*time.Second, Runs: 12}
+	m.Options.ETANotifyAt = 0.8
+	assert.Equal(t, "usually takes 4m12s (p50 of 12 runs), expected to finish at 15:42", m.formatETA(now))
+	assert.Equal(t, "usually takes 4m12s (p50 of 12 runs), expected to finish at Thu 00:02", m.formatETA(now.Add(8*time.Hour+20*time.Minute)))
+	assert.Equal(t, "Command `make build` is about 80% done.\nUsually takes: 4m12s (p50 of 12 runs)\nExpected to finish at 15:38 (in 52s)\nElapsed: 3m20s", m.formatETANotification(now))
+}
+
+func TestETANotification(t *testing.T) {
+	sent := make(chan string, 10)
+	origNotifyProgress := notifyProgress
+	notifyProgress = func(msg string) error {
+		sent <- msg
+		return nil
+	}
+	t.Cleanup(func() { notifyProgress = origNotifyProgress })
+
+	m := NewNotificationMarker(exec.Command("true"), Options{
+		Baseline:    &history.Baseline{P50: 100 * time.Millisecond, Runs: 3},
+		ETANotifyAt: 0.5,
+	}).(*NotificationMarkerImpl)
+	m.Start()
+	select {
+	case msg := <-sent:
+		assert.Contains(t, msg, "is about 50% done.")
+	case <-time.After(2 * time.Second):
+		t.Fatal("no ETA notification")
+	}
+	m.stopBackground()
+	assert.Empty(t, sent, "the ETA notification is only sent once")
+}
+
+func TestFormatMessageLog(t *testing.T) {
+	m := &NotificationMarkerImpl{Command: exec.Command("make", "build")}
+	m.Options.Log = &runlog.Log{Path: "/home/me/.n-cli/logs/20261019-110203-make-build.log", Policy: runlog.KeepOnFailure}
+	msg := m.formatMessage(printedMarkerInfo{exitCode: 2, elapsed: "1s"})
+	assert.Contains(t, msg, "\nLog: /home/me/.n-cli/logs/20261019-110203-make-build.log\nLog URL: file:///home/me/.n-cli/logs/20261019-110203-make-build.log")
+
+	// the log of a successful run is removed
+	msg = m.formatMessage(printedMarkerInfo{succeeded: true, elapsed: "1s"})
+	assert.NotContains(t, msg, "Log:")
+}
+
+func TestIsSuccess(t *testing.T) {
+	m := &NotificationMarkerImpl{}
+	assert.True(t, m.isSuccess(0))
+	assert.False(t, m.isSuccess(1))
+
+	m.Options.SuccessExitCodes = []int{0, 1}
+	assert.True(t, m.isSuccess(1))
+	assert.False(t, m.isSuccess(2))
+
+	m.Options.SuccessExitCodes = []int{3}
+	assert.False(t, m.isSuccess(0))
+}
+
+func TestShouldNotify(t *testing.T) {
+	testCases := []struct {
+		name      string
+		opts      Options
+		succeeded bool
+		elapsed   time.Duration
+		expected  bool
+	}{
+		{name: "defaults notify on success", succeeded: true, elapsed: time.Second, expected: true},
+		{name: "defaults notify on failure", succeeded: false, elapsed: time.Second, expected: true},
+		{name: "shorter than min duration", opts: Options{MinDuration: 10 * time.Second}, succeeded: false, elapsed: 2 * time.Second, expected: false},
+		{name: "longer than min duration", opts: Options{MinDuration: 10 * time.Second}, succeeded: true, elapsed: time.Minute, expected: true},
+		{name: "failure only skips success", opts: Options{NotifyOn: NotifyOnFailure}, succeeded: true, elapsed: time.Second, expected: false},
+		{name: "failure only notifies failure", opts: Options{NotifyOn: NotifyOnFailure}, succeeded: false, elapsed: time.Second, expected: true},
+		{name: "success only skips failure", opts: Options{NotifyOn: NotifyOnSuccess}, succeeded: false, elapsed: time.Second, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &NotificationMarkerImpl{Options: tc.opts}
+			assert.Equal(t, tc.expected, m.shouldNotify(tc.succeeded, tc.elapsed))
+		})
+	}
+}
+
+func TestParseNotifyOn(t *testing.T) {
+	on, err := ParseNotifyOn("")
+	assert.NoError(t, err)
+	assert.Equal(t, NotifyOnAlways, on)
+
+	on, err = ParseNotifyOn("Failure")
+	assert.NoError(t, err)
+	assert.Equal(t, NotifyOnFailure, on)
+
+	_, err = ParseNotifyOn("sometimes")
+	assert.Equal(t, ErrInvalidNotifyOn, err)
+}
+
+func stubNotify(t *testing.T) <-chan string {
+	t.Helper()
+	sent := make(chan string, 100)
+	original := notify
+	notify = func(msg string) error {
+		sent <- msg
+		return nil
+	}
+	t.Cleanup(func() {
+		notify = original
+	})
+	return sent
+}
+
+func stubNotifyProgress(t *testing.T) <-chan string {
+	t.Helper()
+	sent := make(chan string, 100)
+	original := notifyProgress
+	notifyProgress = func(msg string) error {
+		sent <- msg
+		return nil
+	}
+	t.Cleanup(func() {
+		notifyProgress = original
+	})
+	return sent
+}
+
+func TestHeartbeat(t *testing.T) {
+	sent := stubNotifyProgress(t)
+	m := &NotificationMarkerImpl{
+		StartedFrom: time.Now(),
+		Command:     exec.Command("make", "build"),
+		Options:     Options{Heartbeat: 10 * time.Millisecond},
+	}
+	m.Start()
+	select {
+	case msg := <-sent:
+		assert.Contains(t, msg, "Command `make build` is still running.")
+		assert.Contains(t, msg, "Current RSS: unknown")
+	case <-time.After(2 * time.Second):
+		t.Fatal("no heartbeat was sent")
+	}
+	m.stopBackground()
+}
+
+func TestStallDetection(t *testing.T) {
+	sent := stubNotifyProgress(t)
+	originalInterval := stallCheckInterval
+	stallCheckInterval = 5 * time.Millisecond
+	t.Cleanup(func() {
+		stallCheckInterval = originalInterval
+	})
+
+	activity := capture.NewActivity()
+	m := &NotificationMarkerImpl{
+		StartedFrom: time.Now(),
+		Command:     exec.Command("make", "build"),
+		Options:     Options{StallAfter: 20 * time.Millisecond, Activity: activity},
+	}
+	m.Start()
+	defer m.stopBackground()
+
+	select {
+	case msg := <-sent:
+		assert.Contains(t, msg, "Command `make build` may be stuck: no output for")
+	case <-time.After(2 * time.Second):
+		t.Fatal("no stall notification was sent")
+	}
+
+	// the same stall is only reported once
+	select {
+	case msg := <-sent:
+		t.Fatalf("unexpected second notification: %s", msg)
+	case <-time.After(60 * time.Millisecond):
+	}
+
+	// new output re-arms the check
+	io.WriteString(activity.Writer(), "progress\n")
+	select {
+	case <-sent:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stall detection was not re-armed by new output")
+	}
+}
+
+func TestScanLine(t *testing.T) {
+	sent := stubNotifyProgress(t)
+	var matchedPatterns []string
+	m := &NotificationMarkerImpl{
+		StartedFrom: time.Now(),
+		Command:     exec.Command("npm", "run", "dev"),
+		Options: Options{
+			Patterns:        []*regexp.Regexp{regexp.MustCompile(`Compiled \w+`), regexp.MustCompile(`^panic:`)},
+			PatternDebounce: time.Hour,
+			OnPatternMatch: func(pattern string) {
+				matchedPatterns = append(matchedPatterns, pattern)
+			},
+		},
+	}
+
+	m.ScanLine("Compiling...")
+	m.ScanLine("Compiled successfully in 2s")
+	m.ScanLine("Compiled successfully in 1s")
+	m.ScanLine("panic: runtime error")
+	m.patternSends.Wait()
+
+	var msgs []string
+	for len(sent) > 0 {
+		msgs = append(msgs, <-sent)
+	}
+	assert.Len(t, msgs, 2)
+	assert.Contains(t, msgs, "Command `npm run dev` printed a line matching `Compiled \\w+`:\nCompiled successfully in 2s\nElapsed: 0s")
+	assert.Contains(t, msgs, "Command `npm run dev` printed a line matching `^panic:`:\npanic: runtime error\nElapsed: 0s")
+	assert.Len(t, matchedPatterns, 1)
+
+	t.Run("skipped matches are counted once the window has passed", func(t *testing.T) {
+		m.patternState[0].notifiedAt = time.Now().Add(-2 * time.Hour)
+		m.ScanLine("Compiled successfully in 3s")
+		m.patternSends.Wait()
+		assert.Contains(t, <-sent, "Compiled successfully in 3s\n(1 more match since the last notification)")
+		assert.Len(t, matchedPatterns, 1)
+	})
+
+	t.Run("matches are ignored once stopped", func(t *testing.T) {
+		m.stopPatterns()
+		m.ScanLine("panic: again")
+		m.patternSends.Wait()
+		assert.Empty(t, sent)
+	})
+}
+
+// run with -race: output can still be scanned while Done waits
+func TestScanLineDuringDone(t *testing.T) {
+	stubNotify(t)
+	sent := stubNotifyProgress(t)
+	m := &NotificationMarkerImpl{
+		StartedFrom: time.Now(),
+		Command:     exec.Command("npm", "run", "dev"),
+		Options:     Options{Patterns: []*regexp.Regexp{regexp.MustCompile(`error`)}},
+	}
+	scanning := make(chan struct{})
+	go func() {
+		defer close(scanning)
+		for i := 0; i < 50; i++ {
+			m.ScanLine("error")
+		}
+	}()
+	m.Done()
+	<-scanning
+	m.patternSends.Wait()
+	assert.LessOrEqual(t, len(sent), 50)
+}
+
+func TestDoneFailedToStart(t *testing.T) {
+	sent := stubNotify(t)
+	cmd := exec.Command("/usr/local/bin/nonexistent-backup.sh")
+	err := cmd.Run()
+	assert.Error(t, err)
+
+	m := NewNotificationMarker(cmd, Options{NotifyOn: NotifyOnFailure})
+	m.Stopped("FAILED to start: " + err.Error())
+	m.Done()
+	assert.Len(t, sent, 1)
+	assert.Contains(t, <-sent, "Command `/usr/local/bin/nonexistent-backup.sh` FAILED to start: ")
+
+	// without a reason
+	m = NewNotificationMarker(cmd, Options{NotifyOn: NotifyOnFailure})
+	m.Done()
+	assert.Len(t, sent, 1)
+	assert.Contains(t, <-sent, "Command `/usr/local/bin/nonexistent-backup.sh` FAILED to start.")
+}
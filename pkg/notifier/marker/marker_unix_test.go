@@ -0,0 +1,64 @@
+//go:build !windows
+
+package marker
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/capture"
+	"github.com/lba-studio/n-cli/pkg/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoneKilledBySignal(t *testing.T) {
+	sent := stubNotify(t)
+	tail := capture.NewLineBuffer(5)
+	cmd := exec.Command("sh", "-c", "echo segfaulting; kill -SEGV $$")
+	cmd.Stdout = tail.Writer()
+	m := NewNotificationMarker(cmd, Options{OutputTail: tail})
+	require.Error(t, cmd.Run())
+
+	m.Done()
+	require.Len(t, sent, 1)
+	msg := <-sent
+	assert.Contains(t, msg, "Command `sh -c echo segfaulting; kill -SEGV $$` KILLED by SIGSEGV.")
+	assert.Contains(t, msg, "Last 1 lines of output:\nsegfaulting")
+}
+
+func TestDoneKilledOnFailureOnly(t *testing.T) {
+	sent := stubNotify(t)
+	cmd := exec.Command("sh", "-c", "kill -KILL $$")
+	m := NewNotificationMarker(cmd, Options{NotifyOn: NotifyOnFailure})
+	require.Error(t, cmd.Run())
+
+	m.Done()
+	require.Len(t, sent, 1)
+	assert.Contains(t, <-sent, "Command `sh -c kill -KILL $$` KILLED by SIGKILL.")
+}
+
+// run with -race: heartbeats keep going while Retry swaps the command
+func TestRetryDuringHeartbeat(t *testing.T) {
+	sent := stubNotify(t)
+	progress := stubNotifyProgress(t)
+	m := NewNotificationMarker(exec.Command("sleep", "0.05"), Options{
+		Heartbeat:   10 * time.Millisecond,
+		MaxAttempts: 3,
+	}).(*NotificationMarkerImpl)
+	for attempt := 1; attempt <= 3; attempt++ {
+		cmd := m.Command
+		if attempt > 1 {
+			cmd = exec.Command("sleep", "0.05")
+			m.Retry(1, 50*time.Millisecond, cmd)
+		}
+		runner.Run(context.Background(), cmd, runner.Options{OnStart: m.Start})
+	}
+
+	m.Done()
+	assert.NotEmpty(t, progress)
+	require.Len(t, sent, 1)
+	assert.Contains(t, <-sent, "COMPLETE on attempt 3/3")
+}
@@ -10,12 +10,19 @@ import (
 	"time"
 
 	"github.com/lba-studio/n-cli/internal/config"
+	"github.com/lba-studio/n-cli/pkg/notifier/utils"
 )
 
 type Notifier interface {
 	Notify(ctx context.Context, message string) error
 }
 
+// MessageLimiter is implemented by notifiers whose channel caps the length of
+// a message. NotifyTo truncates messages to fit before handing them over.
+type MessageLimiter interface {
+	MaxMessageLength() int
+}
+
 func Notify(msg string) error {
 	return NotifyTo(msg, os.Stdout)
 }
@@ -39,6 +46,9 @@ func NotifyTo(msg string, output io.Writer) error {
 	if cfg.Slack != nil {
 		notifierMap["slack"] = NewSlackNotifier()
 	}
+	if cfg.File != nil {
+		notifierMap["file"] = NewFileNotifierFromConfig(*cfg.File)
+	}
 	for _, entry := range customNotifierEntries(cfg) {
 		notifierMap[entry.label] = NewCustomNotifierFromConfig(entry.cfg)
 	}
@@ -57,6 +67,10 @@ func NotifyTo(msg string, output io.Writer) error {
 		go func(label string, notifier Notifier) {
 			defer wg.Done()
 			logPrefix := fmt.Sprintf("Sent notification to %s", label)
+			msg := msg
+			if limiter, ok := notifier.(MessageLimiter); ok {
+				msg = utils.TruncateMessage(msg, limiter.MaxMessageLength())
+			}
 			if err := notifier.Notify(ctx, msg); err != nil {
 				fmt.Fprintf(output, "%s...ERROR (%s)\n", logPrefix, err.Error())
 				erroredNotifiersChan <- label
@@ -13,6 +13,10 @@ import (
 	restyutils "github.com/lba-studio/n-cli/pkg/resty_utils"
 )
 
+// Slack truncates workflow variables past 4000 characters. The limit applies
+// once messageFormat is filled in, so Notify truncates rather than NotifyTo.
+const slackMaxMessageLength = 4000
+
 type SlackNotifier struct {
 	restyCli   *resty.Client
 	configurer config.Configurer
@@ -43,7 +47,7 @@ func (n *SlackNotifier) Notify(ctx context.Context, msg string) error {
 		return webhook.ErrWebhookMissingWebhookURL
 	}
 	format := cfg.Slack.MessageFormat
-	msg, err = utils.GetMessageFromFormat(format, msg)
+	msg, err = utils.FormatMessage(format, msg, slackMaxMessageLength)
 	if err != nil {
 		return err
 	}
@@ -12,6 +12,11 @@ func (n *SystemNotifier) Notify(ctx context.Context, msg string) error {
 	return beeep.Notify("N: New Notification", msg, "")
 }
 
+// Desktop notifications are cut off by the OS well before this; keep them short.
+func (n *SystemNotifier) MaxMessageLength() int {
+	return 1000
+}
+
 func NewSystemNotifier() Notifier {
 	return &SystemNotifier{}
 }
@@ -21,3 +21,33 @@ func GetMessageFromFormat(format, msg string) (string, error) {
 	msg = strings.Replace(format, MessagePlaceholder, msg, 1)
 	return msg, nil
 }
+
+// FormatMessage puts msg into format like GetMessageFromFormat, truncating msg
+// so that the result fits in limit runes (0 means no limit).
+func FormatMessage(format, msg string, limit int) (string, error) {
+	if limit > 0 && format != "" {
+		overhead := len([]rune(format)) - len([]rune(MessagePlaceholder))
+		// a format that leaves no room is a config problem the API will report
+		limit = max(limit-overhead, 1)
+	}
+	return GetMessageFromFormat(format, TruncateMessage(msg, limit))
+}
+
+const truncationMarker = "\n[…]\n"
+
+// TruncateMessage shortens msg to at most limit runes. The start (which holds
+// the summary) and the end (which holds the latest output) are kept, and the
+// middle is dropped.
+func TruncateMessage(msg string, limit int) string {
+	runes := []rune(msg)
+	if limit <= 0 || len(runes) <= limit {
+		return msg
+	}
+	markerLen := len([]rune(truncationMarker))
+	if limit <= markerLen {
+		return string(runes[:limit])
+	}
+	head := (limit - markerLen) / 3
+	tail := limit - markerLen - head
+	return string(runes[:head]) + truncationMarker + string(runes[len(runes)-tail:])
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateMessage(t *testing.T) {
+	t.Run("short messages are untouched", func(t *testing.T) {
+		assert.Equal(t, "hello", TruncateMessage("hello", 10))
+		assert.Equal(t, "hello", TruncateMessage("hello", 0))
+	})
+
+	t.Run("keeps the start and the end", func(t *testing.T) {
+		msg := "Command `make` FAILED.\n" + strings.Repeat("noise\n", 100) + "error: the important bit"
+		out := TruncateMessage(msg, 80)
+		assert.Len(t, []rune(out), 80)
+		assert.True(t, strings.HasPrefix(out, "Command `make`"))
+		assert.True(t, strings.HasSuffix(out, "error: the important bit"))
+		assert.Contains(t, out, "[…]")
+	})
+
+	t.Run("tiny limits cut hard", func(t *testing.T) {
+		assert.Equal(t, "hel", TruncateMessage("hello world", 3))
+	})
+}
+
+func TestFormatMessage(t *testing.T) {
+	msg := "Command `make` FAILED.\n" + strings.Repeat("noise\n", 100) + "error: the important bit"
+	out, err := FormatMessage("**n-cli**: {{message}} (sent by n-cli)", msg, 80)
+	assert.NoError(t, err)
+	assert.Len(t, []rune(out), 80)
+	assert.True(t, strings.HasPrefix(out, "**n-cli**: Command `make`"))
+	assert.True(t, strings.HasSuffix(out, "error: the important bit (sent by n-cli)"))
+
+	out, err = FormatMessage("", "hello", 80)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", out)
+
+	_, err = FormatMessage("no placeholder", msg, 80)
+	assert.ErrorIs(t, err, ErrMessageFormatMissingPlaceholder)
+}
@@ -0,0 +1,160 @@
+// Package parallel runs several commands at once, with a concurrency limit,
+// and collects the outcome and resource usage of each.
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/capture"
+	"github.com/lba-studio/n-cli/pkg/monitor"
+	"github.com/lba-studio/n-cli/pkg/runner"
+)
+
+type Job struct {
+	// Command is what the summary shows, e.g. the command line.
+	Command string
+	Args    []string
+}
+
+type Status string
+
+const (
+	StatusSucceeded Status = "ok"
+	StatusFailed    Status = "FAILED"
+	// StatusStopped is a command that was stopped because another one failed
+	// with FailFast.
+	StatusStopped Status = "STOPPED"
+	// StatusSkipped is a command that never started, because another one
+	// failed with FailFast or n-cli was interrupted.
+	StatusSkipped     Status = "SKIPPED"
+	StatusInterrupted Status = "INTERRUPTED"
+)
+
+type Result struct {
+	Job      Job
+	Status   Status
+	ExitCode int
+	Elapsed  time.Duration
+	// Usage is nil when the command did not start, or on Windows.
+	Usage *monitor.Usage
+	// Signal is the signal that interrupted the command.
+	Signal os.Signal
+	// Err is set when the command could not be started.
+	Err error
+}
+
+type Options struct {
+	// Concurrency is how many commands run at once. Defaults to 1.
+	Concurrency int
+	// FailFast stops the other commands as soon as one fails.
+	FailFast bool
+	// Stdout and Stderr receive the output of every command, each line
+	// starting with the command's number, e.g. "[2] ".
+	Stdout io.Writer
+	Stderr io.Writer
+	// SuccessExitCodes lists the exit codes that count as success. Defaults to 0 only.
+	SuccessExitCodes []int
+	// KillAfter is how long a stopped command gets to exit before SIGKILL.
+	KillAfter time.Duration
+}
+
+// Run runs jobs and returns their results, in the same order as jobs.
+func Run(ctx context.Context, jobs []Job, opts Options) []Result {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]Result, len(jobs))
+	for i, job := range jobs {
+		results[i] = Result{Job: job, Status: StatusSkipped}
+	}
+
+	var outputMu sync.Mutex
+	slots := make(chan struct{}, max(opts.Concurrency, 1))
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		select {
+		case <-ctx.Done():
+		case slots <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			stdout := capture.NewPrefixWriter(orDiscard(opts.Stdout), &outputMu, Prefix(i, len(jobs)))
+			stderr := capture.NewPrefixWriter(orDiscard(opts.Stderr), &outputMu, Prefix(i, len(jobs)))
+			results[i] = runJob(ctx, job, stdout, stderr, opts)
+			stdout.Flush()
+			stderr.Flush()
+			if results[i].Status == StatusInterrupted || opts.FailFast && results[i].Status == StatusFailed {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// Prefix is what the output lines of the i-th of n commands start with, e.g. "[ 2] ".
+func Prefix(i, n int) string {
+	return fmt.Sprintf("[%*d] ", len(fmt.Sprint(n)), i+1)
+}
+
+func runJob(ctx context.Context, job Job, stdout, stderr io.Writer, opts Options) Result {
+	cmd := exec.Command(job.Args[0], job.Args[1:]...)
+	// commands can't share the terminal's input
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	startedFrom := time.Now()
+	result := runner.Run(ctx, cmd, runner.Options{KillAfter: opts.KillAfter})
+	r := Result{
+		Job:      job,
+		ExitCode: runner.ExitCode(cmd, result),
+		Elapsed:  time.Since(startedFrom),
+		Err:      result.Err,
+	}
+	if cmd.ProcessState != nil {
+		r.Usage, _ = monitor.GetUsage(cmd.ProcessState)
+	}
+	successExitCodes := opts.SuccessExitCodes
+	if len(successExitCodes) == 0 {
+		successExitCodes = []int{0}
+	}
+	if sig, ok := runner.Interrupted(cmd, result); ok {
+		r.Status = StatusInterrupted
+		r.Signal = sig
+	} else if result.Stopped {
+		r.Status = StatusStopped
+	} else if cmd.ProcessState != nil && slices.Contains(successExitCodes, r.ExitCode) {
+		r.Status = StatusSucceeded
+	} else {
+		r.Status = StatusFailed
+	}
+	return r
+}
+
+// ExitCode is the exit code of the first command that failed or was
+// interrupted, in the order of the jobs, or 0 if none did.
+func ExitCode(results []Result) int {
+	for _, r := range results {
+		if r.Status == StatusFailed || r.Status == StatusInterrupted {
+			return r.ExitCode
+		}
+	}
+	return 0
+}
+
+func orDiscard(w io.Writer) io.Writer {
+	if w == nil {
+		return io.Discard
+	}
+	return w
+}
@@ -0,0 +1,83 @@
+//go:build !windows
+
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func shellJob(commandLine string) Job {
+	return Job{Command: commandLine, Args: []string{"sh", "-c", commandLine}}
+}
+
+func statuses(results []Result) []Status {
+	var s []Status
+	for _, r := range results {
+		s = append(s, r.Status)
+	}
+	return s
+}
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	results := Run(context.Background(), []Job{
+		shellJob("echo one; echo oops >&2"),
+		shellJob("exit 3"),
+		shellJob("printf 'no newline'"),
+	}, Options{Concurrency: 2, Stdout: &stdout, Stderr: &stderr})
+
+	require.Len(t, results, 3)
+	assert.Equal(t, []Status{StatusSucceeded, StatusFailed, StatusSucceeded}, statuses(results))
+	assert.Equal(t, 3, results[1].ExitCode)
+	assert.Equal(t, 3, ExitCode(results))
+	for _, r := range results {
+		assert.NotNil(t, r.Usage)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	sort.Strings(lines)
+	assert.Equal(t, []string{"[1] one", "[3] no newline"}, lines)
+	assert.Equal(t, "[1] oops\n", stderr.String())
+}
+
+func TestRunConcurrency(t *testing.T) {
+	startedFrom := time.Now()
+	results := Run(context.Background(), []Job{
+		shellJob("sleep 0.3"),
+		shellJob("sleep 0.3"),
+		shellJob("sleep 0.3"),
+	}, Options{Concurrency: 2})
+	assert.Equal(t, []Status{StatusSucceeded, StatusSucceeded, StatusSucceeded}, statuses(results))
+	assert.Equal(t, 0, ExitCode(results))
+	// two rounds: the third command had to wait for a free slot
+	assert.GreaterOrEqual(t, time.Since(startedFrom), 600*time.Millisecond)
+}
+
+func TestRunFailFast(t *testing.T) {
+	startedFrom := time.Now()
+	results := Run(context.Background(), []Job{
+		shellJob("sleep 10"),
+		shellJob("sleep 0.1; exit 2"),
+		shellJob("true"),
+	}, Options{Concurrency: 2, FailFast: true, KillAfter: time.Second})
+	assert.Equal(t, []Status{StatusStopped, StatusFailed, StatusSkipped}, statuses(results))
+	assert.Equal(t, 2, ExitCode(results))
+	assert.Less(t, time.Since(startedFrom), 5*time.Second)
+}
+
+func TestRunAll(t *testing.T) {
+	results := Run(context.Background(), []Job{
+		shellJob("exit 2"),
+		shellJob("exit 0"),
+		shellJob("exit 1"),
+	}, Options{Concurrency: 1, SuccessExitCodes: []int{0, 1}})
+	assert.Equal(t, []Status{StatusFailed, StatusSucceeded, StatusSucceeded}, statuses(results))
+	assert.Equal(t, 2, ExitCode(results))
+}
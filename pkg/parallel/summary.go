@@ -0,0 +1,86 @@
+package parallel
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/formatter"
+	"github.com/lba-studio/n-cli/pkg/runner"
+)
+
+// maxSummaryCommandLength keeps long command lines from breaking the table.
+const maxSummaryCommandLength = 60
+
+// FormatSummary describes the outcome of every command, e.g.
+//
+//	1 of 3 commands FAILED, 1 stopped.
+//	Elapsed: 12s
+//	#  STATUS      ELAPSED  PEAK MEMORY  COMMAND
+//	1  ok          12s      150.2 MiB    make lint
+//	2  FAILED (2)  3.2s     80.0 MiB     make test
+//	3  STOPPED     3.1s     1.1 GiB      make e2e
+func FormatSummary(results []Result, elapsed time.Duration) string {
+	counts := map[Status]int{}
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	failed := counts[StatusFailed] + counts[StatusInterrupted]
+	var b strings.Builder
+	commands := "commands"
+	if len(results) == 1 {
+		commands = "command"
+	}
+	if failed == 0 && counts[StatusSucceeded] == len(results) {
+		fmt.Fprintf(&b, "%d %s COMPLETE", len(results), commands)
+	} else {
+		fmt.Fprintf(&b, "%d of %d %s FAILED", failed, len(results), commands)
+	}
+	for _, status := range []Status{StatusStopped, StatusSkipped} {
+		if counts[status] > 0 {
+			fmt.Fprintf(&b, ", %d %s", counts[status], strings.ToLower(string(status)))
+		}
+	}
+	fmt.Fprintf(&b, ".\nElapsed: %s\n", roundElapsed(elapsed))
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tSTATUS\tELAPSED\tPEAK MEMORY\tCOMMAND")
+	for i, r := range results {
+		memory := "-"
+		if r.Usage != nil {
+			memory = formatter.PrettyPrintBytes(r.Usage.MaxRSS)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, formatStatus(r), formatElapsed(r), memory, formatter.TruncateLine(r.Job.Command, maxSummaryCommandLength))
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func formatStatus(r Result) string {
+	switch r.Status {
+	case StatusFailed:
+		return fmt.Sprintf("%s (%d)", r.Status, r.ExitCode)
+	case StatusInterrupted:
+		return fmt.Sprintf("%s (%s)", r.Status, runner.SignalName(r.Signal))
+	default:
+		return string(r.Status)
+	}
+}
+
+func formatElapsed(r Result) string {
+	if r.Status == StatusSkipped {
+		return "-"
+	}
+	return roundElapsed(r.Elapsed).String()
+}
+
+func roundElapsed(d time.Duration) time.Duration {
+	switch {
+	case d >= 10*time.Second:
+		return d.Round(time.Second)
+	case d >= time.Second:
+		return d.Round(100 * time.Millisecond)
+	default:
+		return d.Round(time.Millisecond)
+	}
+}
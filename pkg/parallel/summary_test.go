@@ -0,0 +1,35 @@
+package parallel
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatSummary(t *testing.T) {
+	results := []Result{
+		{Job: Job{Command: "make lint"}, Status: StatusSucceeded, Elapsed: 12300 * time.Millisecond, Usage: &monitor.Usage{MaxRSS: 150 * 1024 * 1024}},
+		{Job: Job{Command: "make test"}, Status: StatusFailed, ExitCode: 2, Elapsed: 3240 * time.Millisecond, Usage: &monitor.Usage{MaxRSS: 80 * 1024 * 1024}},
+		{Job: Job{Command: "make e2e"}, Status: StatusInterrupted, Signal: syscall.SIGINT, ExitCode: 130, Elapsed: 3100 * time.Millisecond},
+		{Job: Job{Command: "make docs"}, Status: StatusSkipped},
+	}
+	assert.Equal(t, `2 of 4 commands FAILED, 1 skipped.
+Elapsed: 15s
+#  STATUS                ELAPSED  PEAK MEMORY  COMMAND
+1  ok                    12s      150.0 MiB    make lint
+2  FAILED (2)            3.2s     80.0 MiB     make test
+3  INTERRUPTED (SIGINT)  3.1s     -            make e2e
+4  SKIPPED               -        -            make docs`, FormatSummary(results, 15*time.Second))
+
+	assert.Equal(t, "1 command COMPLETE.\nElapsed: 1s\n#  STATUS  ELAPSED  PEAK MEMORY  COMMAND\n1  ok      1s       -            true", FormatSummary([]Result{
+		{Job: Job{Command: "true"}, Status: StatusSucceeded, Elapsed: time.Second},
+	}, time.Second))
+
+	assert.Contains(t, FormatSummary([]Result{
+		{Job: Job{Command: "sleep 5"}, Status: StatusStopped, Elapsed: 200 * time.Millisecond},
+		{Job: Job{Command: "exit 4"}, Status: StatusFailed, ExitCode: 4, Elapsed: 12 * time.Millisecond},
+	}, 212345*time.Microsecond), "1 of 2 commands FAILED, 1 stopped.\nElapsed: 212ms\n")
+}
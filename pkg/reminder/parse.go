@@ -0,0 +1,57 @@
+package reminder
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidDelay = errors.New("invalid delay (expected a duration of at least 1s, such as 25m or 1h30m)")
+	ErrInvalidClock = errors.New("invalid time (expected e.g. 17:30, 5:30pm or 5pm)")
+)
+
+var clockLayouts = []string{"15:04", "3:04pm", "3pm"}
+
+// MinDelay keeps a reminder from being sent before it has been fully set up.
+const MinDelay = time.Second
+
+// ParseDelay parses how long to wait, e.g. "25m".
+func ParseDelay(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d < MinDelay {
+		return 0, ErrInvalidDelay
+	}
+	return d, nil
+}
+
+// ParseClock returns the next time the local clock shows s, e.g. "17:30":
+// later today, or tomorrow if that time has already passed.
+func ParseClock(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	for _, layout := range clockLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		now = now.Local()
+		at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+		if !at.After(now) {
+			// built from the date rather than adding 24h, so that DST changes
+			// keep the same wall clock time
+			at = time.Date(now.Year(), now.Month(), now.Day()+1, t.Hour(), t.Minute(), 0, 0, time.Local)
+		}
+		return at, nil
+	}
+	return time.Time{}, ErrInvalidClock
+}
+
+// FormatDue formats when a reminder is due, with the weekday if it is not
+// today.
+func FormatDue(at, now time.Time) string {
+	at, now = at.Local(), now.Local()
+	if at.YearDay() != now.YearDay() || at.Year() != now.Year() {
+		return at.Format("Mon 15:04")
+	}
+	return at.Format("15:04")
+}
@@ -0,0 +1,69 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDelay(t *testing.T) {
+	d, err := ParseDelay("25m")
+	require.NoError(t, err)
+	assert.Equal(t, 25*time.Minute, d)
+	d, err = ParseDelay("1h30m")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, d)
+
+	for _, s := range []string{"", "25", "soon", "0s", "-5m", "1ns", "500ms"} {
+		_, err := ParseDelay(s)
+		assert.ErrorIs(t, err, ErrInvalidDelay, s)
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 15, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"17:30", time.Date(2026, 10, 19, 17, 30, 0, 0, time.Local)},
+		{"5:30pm", time.Date(2026, 10, 19, 17, 30, 0, 0, time.Local)},
+		{"5 PM", time.Date(2026, 10, 19, 17, 0, 0, 0, time.Local)},
+		{"09:15", time.Date(2026, 10, 20, 9, 15, 0, 0, time.Local)},
+		{"8:00", time.Date(2026, 10, 20, 8, 0, 0, 0, time.Local)},
+		{"0:05", time.Date(2026, 10, 20, 0, 5, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := ParseClock(tt.in, now)
+		require.NoError(t, err, tt.in)
+		assert.True(t, tt.want.Equal(got), "%s: got %s", tt.in, got)
+	}
+
+	for _, s := range []string{"", "25:00", "17", "noon", "17:30:00"} {
+		_, err := ParseClock(s, now)
+		assert.ErrorIs(t, err, ErrInvalidClock, s)
+	}
+}
+
+func TestParseClockAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = loc
+
+	// clocks go back an hour overnight
+	now := time.Date(2026, 10, 24, 18, 0, 0, 0, loc)
+	got, err := ParseClock("9:00", now)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10-25 09:00 CET", got.Format("2006-01-02 15:04 MST"))
+}
+
+func TestFormatDue(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 15, 0, 0, time.Local)
+	assert.Equal(t, "17:30", FormatDue(time.Date(2026, 10, 19, 17, 30, 0, 0, time.Local), now))
+	assert.Equal(t, "Tue 08:00", FormatDue(time.Date(2026, 10, 20, 8, 0, 0, 0, time.Local), now))
+}
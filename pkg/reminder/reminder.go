@@ -0,0 +1,117 @@
+// Package reminder keeps track of the reminders scheduled with n-cli remind.
+package reminder
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/state"
+)
+
+var ErrNotFound = errors.New("no such reminder")
+
+// Reminder is a notification to send at a given time.
+type Reminder struct {
+	ID      string    `json:"id"`
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+	Created time.Time `json:"created"`
+	// Pid is the background process that sends the reminder.
+	Pid int `json:"pid,omitempty"`
+}
+
+// Store keeps one file per pending reminder, so that the background
+// processes never write to the same file.
+type Store struct {
+	state *state.Store
+}
+
+// DefaultDir is ~/.n-cli/reminders.
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".n-cli", "reminders"), nil
+}
+
+func NewStore(dir string) *Store {
+	return &Store{state: state.NewStore(dir)}
+}
+
+// Add saves r under a new ID, which it returns.
+func (s *Store) Add(r Reminder) (Reminder, error) {
+	for {
+		id, err := newID()
+		if err != nil {
+			return r, err
+		}
+		if _, err := s.Get(id); errors.Is(err, ErrNotFound) {
+			r.ID = id
+			break
+		} else if err != nil {
+			return r, err
+		}
+	}
+	return r, s.Save(r)
+}
+
+// Save updates a reminder that was added before.
+func (s *Store) Save(r Reminder) error {
+	return s.state.Save(r.ID, r)
+}
+
+func (s *Store) Get(id string) (Reminder, error) {
+	var r Reminder
+	if err := s.state.Load(id, &r); err != nil {
+		if errors.Is(err, state.ErrInvalidName) {
+			return r, ErrNotFound
+		}
+		return r, err
+	}
+	if r.ID == "" {
+		return r, ErrNotFound
+	}
+	return r, nil
+}
+
+// List returns the pending reminders, soonest first. Unreadable files are
+// skipped.
+func (s *Store) List() ([]Reminder, error) {
+	ids, err := s.state.Names()
+	if err != nil {
+		return nil, err
+	}
+	reminders := make([]Reminder, 0, len(ids))
+	for _, id := range ids {
+		if r, err := s.Get(id); err == nil {
+			reminders = append(reminders, r)
+		}
+	}
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].At.Before(reminders[j].At)
+	})
+	return reminders, nil
+}
+
+// Remove deletes a reminder. The background process notices and exits
+// without sending it.
+func (s *Store) Remove(id string) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	return s.state.Delete(id)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
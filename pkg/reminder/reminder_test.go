@@ -0,0 +1,52 @@
+package reminder
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "reminders"))
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	later, err := s.Add(Reminder{Message: "go home", At: now.Add(8 * time.Hour), Created: now})
+	require.NoError(t, err)
+	soon, err := s.Add(Reminder{Message: "stand-up", At: now.Add(25 * time.Minute), Created: now})
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{6}$`, soon.ID)
+	assert.NotEqual(t, soon.ID, later.ID)
+
+	soon.Pid = 42
+	require.NoError(t, s.Save(soon))
+	got, err := s.Get(soon.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 42, got.Pid)
+	assert.True(t, got.At.Equal(soon.At))
+
+	list, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "stand-up", list[0].Message)
+	assert.Equal(t, "go home", list[1].Message)
+
+	require.NoError(t, s.Remove(soon.ID))
+	assert.ErrorIs(t, s.Remove(soon.ID), ErrNotFound)
+	_, err = s.Get(soon.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Get("../config")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	list, err = s.List()
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestListWithoutReminders(t *testing.T) {
+	list, err := NewStore(filepath.Join(t.TempDir(), "missing")).List()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
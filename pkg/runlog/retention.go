@@ -0,0 +1,79 @@
+package runlog
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Retention limits how many logs are kept. Zero means no limit.
+type Retention struct {
+	MaxFiles int
+	MaxBytes int64
+}
+
+// Info describes a log in the logs directory.
+type Info struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// List returns the logs in dir, newest first. A missing dir has no logs.
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var logs []Info
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".log") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, Info{Path: filepath.Join(dir, entry.Name()), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	// names start with the time the log was created, to the second
+	slices.SortFunc(logs, func(a, b Info) int {
+		if c := strings.Compare(createdAt(b), createdAt(a)); c != 0 {
+			return c
+		}
+		return b.ModTime.Compare(a.ModTime)
+	})
+	return logs, nil
+}
+
+func createdAt(log Info) string {
+	name := filepath.Base(log.Path)
+	return name[:min(len(timestampFormat), len(name))]
+}
+
+// Prune removes the oldest logs in dir until it is within r. The newest log
+// is always kept.
+func Prune(dir string, r Retention) error {
+	logs, err := List(dir)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for i, log := range logs {
+		total += log.Size
+		if i == 0 {
+			continue
+		}
+		if (r.MaxFiles > 0 && i >= r.MaxFiles) || (r.MaxBytes > 0 && total > r.MaxBytes) {
+			if err := os.Remove(log.Path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
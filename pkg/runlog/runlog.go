@@ -0,0 +1,139 @@
+// Package runlog saves the complete output of n-cli runs to log files.
+package runlog
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidPolicy = errors.New("invalid log policy (expected failure or always)")
+
+// Policy decides which logs are kept once the command has finished.
+type Policy string
+
+const (
+	KeepOnFailure Policy = "failure"
+	KeepAlways    Policy = "always"
+)
+
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(strings.ToLower(s)); p {
+	case KeepOnFailure, KeepAlways:
+		return p, nil
+	default:
+		return "", ErrInvalidPolicy
+	}
+}
+
+// DefaultDir is ~/.n-cli/logs.
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".n-cli", "logs"), nil
+}
+
+// Log is a log file that the output of a command is written to. It is safe
+// for concurrent use, e.g. as both the stdout and stderr of a command.
+type Log struct {
+	Path   string
+	Policy Policy
+	// BaseURL is where the logs directory is served, e.g.
+	// https://ci.example.com/logs. URL uses file:// links without it.
+	BaseURL string
+
+	mu  sync.Mutex
+	f   *os.File
+	err error
+}
+
+// timestampFormat sorts in the same order as the logs were created.
+const timestampFormat = "20060102-150405"
+
+// maxNameLength keeps file names readable (and within filesystem limits).
+const maxNameLength = 40
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// Create creates a new log in dir, named <timestamp>-<command>.log.
+func Create(dir, command string, now time.Time, policy Policy) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	name := strings.Trim(unsafeNameChars.ReplaceAllString(command, "-"), "-")
+	if len(name) > maxNameLength {
+		name = strings.TrimRight(name[:maxNameLength], "-")
+	}
+	base := now.Format(timestampFormat)
+	if name != "" {
+		base += "-" + name
+	}
+	// commands can start within the same second
+	path := filepath.Join(dir, base+".log")
+	for i := 2; ; i++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			path = filepath.Join(dir, fmt.Sprintf("%s-%d.log", base, i))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Log{Path: path, Policy: policy, f: f}, nil
+	}
+}
+
+// Write never fails: the log is written alongside the terminal, and a full
+// disk must not break the command's output. Err reports the first error.
+func (l *Log) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.Write(p); err != nil && l.err == nil {
+		l.err = err
+	}
+	return len(p), nil
+}
+
+// Err returns the first error that Write ran into, if any.
+func (l *Log) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Keep reports whether the log is kept once the command has finished.
+func (l *Log) Keep(succeeded bool) bool {
+	return l.Policy == KeepAlways || !succeeded
+}
+
+// URL links to the log: under BaseURL if set, or as a file:// URL.
+func (l *Log) URL() string {
+	if l.BaseURL != "" {
+		return strings.TrimSuffix(l.BaseURL, "/") + "/" + url.PathEscape(filepath.Base(l.Path))
+	}
+	path := filepath.ToSlash(l.Path)
+	// Windows paths such as C:/logs need a leading slash
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// Close closes the log, and removes it unless Keep says otherwise.
+func (l *Log) Close(succeeded bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := l.f.Close()
+	if !l.Keep(succeeded) {
+		return os.Remove(l.Path)
+	}
+	return err
+}
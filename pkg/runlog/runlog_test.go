@@ -0,0 +1,113 @@
+package runlog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	now := time.Date(2026, 10, 19, 11, 2, 3, 0, time.UTC)
+
+	l, err := Create(dir, "make build && ./run --flag=\"a b\"", now, KeepOnFailure)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20261019-110203-make-build-run-flag-a-b.log"), l.Path)
+	io.WriteString(l, "hello\n")
+	require.NoError(t, l.Close(false))
+	b, err := os.ReadFile(l.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(b))
+
+	// same command in the same second
+	l2, err := Create(dir, "make build && ./run --flag=\"a b\"", now, KeepOnFailure)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20261019-110203-make-build-run-flag-a-b-2.log"), l2.Path)
+	require.NoError(t, l2.Close(true))
+	assert.NoFileExists(t, l2.Path, "logs of successful runs are removed")
+
+	l3, err := Create(dir, "/usr/bin/a-really-long-command-name-that-goes-on-and-on --and-on", now, KeepAlways)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20261019-110203-usr-bin-a-really-long-command-name-that.log"), l3.Path)
+	require.NoError(t, l3.Close(true))
+	assert.FileExists(t, l3.Path)
+}
+
+func TestWriteKeepsGoing(t *testing.T) {
+	l, err := Create(t.TempDir(), "make", time.Now(), KeepAlways)
+	require.NoError(t, err)
+	require.NoError(t, l.Err())
+	// stand-in for a full disk
+	require.NoError(t, l.f.Close())
+
+	n, err := io.WriteString(l, "hello\n")
+	assert.NoError(t, err, "a failing log must not break the command's output")
+	assert.Equal(t, 6, n)
+	first := l.Err()
+	assert.ErrorIs(t, first, os.ErrClosed)
+	io.WriteString(l, "world\n")
+	assert.Same(t, first, l.Err(), "only the first error is kept")
+}
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("Always")
+	assert.NoError(t, err)
+	assert.Equal(t, KeepAlways, p)
+	_, err = ParsePolicy("sometimes")
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+}
+
+func TestURL(t *testing.T) {
+	l := &Log{Path: "/home/me/.n-cli/logs/20261019-110203-make build.log"}
+	assert.Equal(t, "file:///home/me/.n-cli/logs/20261019-110203-make%20build.log", l.URL())
+
+	l.BaseURL = "https://ci.example.com/logs/"
+	assert.Equal(t, "https://ci.example.com/logs/20261019-110203-make%20build.log", l.URL())
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0o600))
+	}
+	write("20261019-110201-a.log", 100)
+	write("20261019-110202-b.log", 100)
+	write("20261019-110203-c.log", 100)
+	write("20261019-110204-d.log", 300)
+	// created in the same second as c, but later
+	write("20261019-110203-b2.log", 0)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "20261019-110203-b2.log"), time.Now(), time.Now().Add(time.Minute)))
+	write("notes.txt", 1000)
+
+	names := func() []string {
+		logs, err := List(dir)
+		require.NoError(t, err)
+		var names []string
+		for _, l := range logs {
+			names = append(names, filepath.Base(l.Path))
+		}
+		return names
+	}
+	assert.Equal(t, []string{"20261019-110204-d.log", "20261019-110203-b2.log", "20261019-110203-c.log", "20261019-110202-b.log", "20261019-110201-a.log"}, names())
+	require.NoError(t, os.Remove(filepath.Join(dir, "20261019-110203-b2.log")))
+
+	require.NoError(t, Prune(dir, Retention{MaxFiles: 3}))
+	assert.Equal(t, []string{"20261019-110204-d.log", "20261019-110203-c.log", "20261019-110202-b.log"}, names())
+
+	require.NoError(t, Prune(dir, Retention{MaxBytes: 450}))
+	assert.Equal(t, []string{"20261019-110204-d.log", "20261019-110203-c.log"}, names())
+
+	// the newest log is kept even when it is too big on its own
+	require.NoError(t, Prune(dir, Retention{MaxBytes: 10}))
+	assert.Equal(t, []string{"20261019-110204-d.log"}, names())
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+
+	logs, err := List(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, logs)
+}
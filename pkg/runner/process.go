@@ -0,0 +1,194 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"time"
+)
+
+type Options struct {
+	// KillSignal is sent to the command's process group when ctx is done.
+	// Defaults to SIGTERM.
+	KillSignal os.Signal
+	// KillAfter is how long to wait after KillSignal before sending SIGKILL.
+	// Zero waits for the command to exit on its own.
+	KillAfter time.Duration
+	// OnStart is called once the command is running.
+	OnStart func()
+	// PTY runs the command in a pseudo-terminal, so that it behaves as it
+	// would in a terminal even when its output is captured. Its stdout and
+	// stderr are both copied to cmd.Stdout. See PTYSupported.
+	PTY bool
+}
+
+type Result struct {
+	// Err is the error returned while starting or waiting for the command.
+	Err error
+	// Stopped is set when ctx ended before the command exited.
+	Stopped bool
+	// TimedOut is set when ctx ended because its deadline passed.
+	TimedOut bool
+	// Killed is set when the command did not exit within KillAfter.
+	Killed bool
+	// Signal is the first signal n-cli received (and forwarded) while the
+	// command was running.
+	Signal os.Signal
+}
+
+// Run starts cmd in its own process group and waits for it to exit. When ctx
+// is done first, the whole group is sent opts.KillSignal, and SIGKILL once
+// opts.KillAfter has passed. SIGINT, SIGTERM, SIGHUP and SIGQUIT sent to n-cli
+// are forwarded to the group instead of stopping n-cli.
+func Run(ctx context.Context, cmd *exec.Cmd, opts Options) Result {
+	var p *pty
+	if opts.PTY {
+		var err error
+		if p, err = attachPTY(cmd); err != nil {
+			return Result{Err: err}
+		}
+		defer p.close()
+	} else {
+		restore := setProcessGroup(cmd)
+		defer restore()
+	}
+
+	// catch signals before starting, so that none of them can take n-cli down
+	// before the command's result is known
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return Result{Err: err}
+	}
+	if p != nil {
+		p.start()
+	}
+	if opts.OnStart != nil {
+		opts.OnStart()
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- cmd.Wait()
+	}()
+
+	killSignal := opts.KillSignal
+	if killSignal == nil {
+		killSignal = defaultKillSignal
+	}
+	var killTimer *time.Timer
+	defer func() {
+		if killTimer != nil {
+			killTimer.Stop()
+		}
+	}()
+
+	var result Result
+	var killAfter <-chan time.Time
+	done := ctx.Done()
+	for {
+		select {
+		case result.Err = <-waitErr:
+			return result
+		case sig := <-signals:
+			if result.Signal == nil {
+				result.Signal = sig
+			}
+			_ = forwardSignal(cmd, sig)
+		case <-done:
+			done = nil
+			result.Stopped = true
+			result.TimedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
+			_ = signalProcessGroup(cmd, killSignal)
+			if opts.KillAfter > 0 {
+				killTimer = time.NewTimer(opts.KillAfter)
+				killAfter = killTimer.C
+			}
+		case <-killAfter:
+			killAfter = nil
+			result.Killed = true
+			_ = signalProcessGroup(cmd, os.Kill)
+		}
+	}
+}
+
+// Interrupted reports which signal interrupted the command: either one that
+// n-cli forwarded, or one the command received directly (e.g. Ctrl-C while it
+// owns the terminal).
+func Interrupted(cmd *exec.Cmd, result Result) (os.Signal, bool) {
+	if result.Signal != nil {
+		return result.Signal, true
+	}
+	if result.Stopped || cmd.ProcessState == nil {
+		return nil, false
+	}
+	n, ok := TerminatingSignal(cmd.ProcessState)
+	if !ok {
+		return nil, false
+	}
+	for _, sig := range forwardedSignals {
+		if signalNumber(sig) == n {
+			return sig, true
+		}
+	}
+	return nil, false
+}
+
+// Exit codes used when the command itself did not provide one, following the
+// conventions of POSIX shells and coreutils timeout.
+const (
+	ExitCodeTimedOut      = 124
+	ExitCodeCannotExecute = 126
+	ExitCodeNotFound      = 127
+	exitCodeSignalBase    = 128
+)
+
+// ExitCode returns the exit code that n-cli should exit with after Run.
+func ExitCode(cmd *exec.Cmd, result Result) int {
+	if cmd.ProcessState == nil {
+		// a missing path fails in exec rather than in the PATH lookup;
+		// anything else (EACCES, ENOEXEC) means the file cannot be run
+		if errors.Is(result.Err, exec.ErrNotFound) || errors.Is(result.Err, fs.ErrNotExist) {
+			return ExitCodeNotFound
+		}
+		return ExitCodeCannotExecute
+	}
+	if result.Signal != nil {
+		return exitCodeSignalBase + signalNumber(result.Signal)
+	}
+	if result.TimedOut {
+		if result.Killed {
+			return exitCodeSignalBase + 9
+		}
+		return ExitCodeTimedOut
+	}
+	if code := cmd.ProcessState.ExitCode(); code >= 0 {
+		return code
+	}
+	if sig, ok := TerminatingSignal(cmd.ProcessState); ok {
+		return exitCodeSignalBase + sig
+	}
+	return 1
+}
+
+// Sleep waits for d. It returns early with the signal if n-cli receives one of
+// the signals that Run forwards, and nil otherwise.
+func Sleep(d time.Duration) os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case sig := <-signals:
+		return sig
+	case <-timer.C:
+		return nil
+	}
+}
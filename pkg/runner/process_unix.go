@@ -0,0 +1,79 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var defaultKillSignal os.Signal = syscall.SIGTERM
+
+// forwardedSignals are relayed to the command instead of stopping n-cli.
+var forwardedSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// setProcessGroup makes cmd start in its own process group, so that signals can
+// reach everything it spawns. If n-cli owns the terminal, the new group becomes
+// the foreground group: the command can keep reading from the terminal, and
+// keyboard signals such as Ctrl-C go straight to it. The returned func gives
+// the terminal back to n-cli.
+func setProcessGroup(cmd *exec.Cmd) (restore func()) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+
+	ttyFd := int(os.Stdin.Fd())
+	if cmd.Stdin != os.Stdin || !ownsTerminal(ttyFd) {
+		return func() {}
+	}
+	cmd.SysProcAttr.Foreground = true
+	cmd.SysProcAttr.Ctty = ttyFd
+	return func() {
+		// we're a background group now; without ignoring SIGTTOU, taking the
+		// terminal back would stop n-cli
+		signal.Ignore(syscall.SIGTTOU)
+		defer signal.Reset(syscall.SIGTTOU)
+		_ = unix.IoctlSetPointerInt(ttyFd, unix.TIOCSPGRP, unix.Getpgrp())
+	}
+}
+
+func ownsTerminal(fd int) bool {
+	foreground, err := unix.IoctlGetInt(fd, unix.TIOCGPGRP)
+	return err == nil && foreground == unix.Getpgrp()
+}
+
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return cmd.Process.Signal(sig)
+	}
+	// the command's pid is also its process group id
+	return syscall.Kill(-cmd.Process.Pid, s)
+}
+
+func forwardSignal(cmd *exec.Cmd, sig os.Signal) error {
+	return signalProcessGroup(cmd, sig)
+}
+
+// TerminatingSignal returns the number of the signal that terminated the process, if any.
+func TerminatingSignal(state *os.ProcessState) (int, bool) {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return 0, false
+	}
+	return int(status.Signal()), true
+}
+
+// Detach makes cmd start in a session of its own, so that it keeps running
+// after the terminal that started it is closed.
+func Detach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+}
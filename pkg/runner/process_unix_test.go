@@ -0,0 +1,139 @@
+//go:build !windows
+
+package runner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunExitCodes(t *testing.T) {
+	t.Run("propagates the command's exit code", func(t *testing.T) {
+		cmd := exec.Command("sh", "-c", "exit 3")
+		result := Run(context.Background(), cmd, Options{})
+		assert.False(t, result.Stopped)
+		assert.Equal(t, 3, ExitCode(cmd, result))
+	})
+
+	t.Run("command not found", func(t *testing.T) {
+		cmd := exec.Command("n-cli-definitely-not-a-command")
+		result := Run(context.Background(), cmd, Options{})
+		assert.Error(t, result.Err)
+		assert.Equal(t, ExitCodeNotFound, ExitCode(cmd, result))
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		cmd := exec.Command(filepath.Join(t.TempDir(), "backup.sh"))
+		result := Run(context.Background(), cmd, Options{})
+		assert.Error(t, result.Err)
+		assert.Equal(t, ExitCodeNotFound, ExitCode(cmd, result))
+	})
+
+	t.Run("not executable", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "backup.sh")
+		require.NoError(t, os.WriteFile(path, []byte("echo hi\n"), 0o644))
+		cmd := exec.Command(path)
+		result := Run(context.Background(), cmd, Options{})
+		assert.Error(t, result.Err)
+		assert.Equal(t, ExitCodeCannotExecute, ExitCode(cmd, result))
+	})
+
+	t.Run("killed by a signal", func(t *testing.T) {
+		cmd := exec.Command("sh", "-c", "kill -TERM $$")
+		result := Run(context.Background(), cmd, Options{})
+		assert.Equal(t, 128+int(syscall.SIGTERM), ExitCode(cmd, result))
+	})
+}
+
+func TestRunTimeout(t *testing.T) {
+	t.Run("stops the whole process group", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		started := false
+		// the background sleep keeps stdout open, so Wait only returns once it is gone too
+		cmd := exec.Command("sh", "-c", "sleep 30 & sleep 30")
+		cmd.Stdout = &nopWriter{}
+		begin := time.Now()
+		result := Run(ctx, cmd, Options{OnStart: func() { started = true }})
+		assert.True(t, started)
+		assert.True(t, result.Stopped)
+		assert.True(t, result.TimedOut)
+		assert.False(t, result.Killed)
+		assert.Less(t, time.Since(begin), 10*time.Second)
+		assert.Equal(t, ExitCodeTimedOut, ExitCode(cmd, result))
+	})
+
+	t.Run("escalates to SIGKILL after kill-after", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		cmd := exec.Command("sh", "-c", `trap "" TERM; sleep 30`)
+		result := Run(ctx, cmd, Options{KillAfter: 100 * time.Millisecond})
+		assert.True(t, result.TimedOut)
+		assert.True(t, result.Killed)
+		assert.Equal(t, 128+9, ExitCode(cmd, result))
+	})
+
+	t.Run("custom kill signal", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		cmd := exec.Command("sleep", "30")
+		result := Run(ctx, cmd, Options{KillSignal: syscall.SIGINT})
+		require.NotNil(t, cmd.ProcessState)
+		sig, ok := TerminatingSignal(cmd.ProcessState)
+		assert.True(t, ok)
+		assert.Equal(t, int(syscall.SIGINT), sig)
+		assert.Equal(t, ExitCodeTimedOut, ExitCode(cmd, result))
+	})
+}
+
+func TestRunForwardsSignals(t *testing.T) {
+	cmd := exec.Command("sh", "-c", `trap "exit 3" HUP; while :; do sleep 0.01; done`)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	}()
+	result := Run(context.Background(), cmd, Options{})
+	assert.Equal(t, syscall.SIGHUP, result.Signal)
+	assert.Equal(t, 3, cmd.ProcessState.ExitCode())
+	assert.Equal(t, 128+int(syscall.SIGHUP), ExitCode(cmd, result))
+
+	sig, ok := Interrupted(cmd, result)
+	assert.True(t, ok)
+	assert.Equal(t, "SIGHUP", SignalName(sig))
+}
+
+func TestInterruptedBySignalToCommand(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "kill -INT $$")
+	result := Run(context.Background(), cmd, Options{})
+	sig, ok := Interrupted(cmd, result)
+	assert.True(t, ok)
+	assert.Equal(t, syscall.SIGINT, sig)
+	assert.Equal(t, 130, ExitCode(cmd, result))
+
+	cmd = exec.Command("sh", "-c", "kill -USR1 $$")
+	result = Run(context.Background(), cmd, Options{})
+	_, ok = Interrupted(cmd, result)
+	assert.False(t, ok)
+}
+
+type nopWriter struct{}
+
+func (*nopWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestParseSignal(t *testing.T) {
+	for _, input := range []string{"TERM", "sigterm", "SIGTERM", "15"} {
+		sig, err := ParseSignal(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, syscall.SIGTERM, sig, input)
+	}
+	_, err := ParseSignal("SIGNOPE")
+	assert.ErrorIs(t, err, ErrUnknownSignal)
+}
@@ -0,0 +1,43 @@
+//go:build windows
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+var defaultKillSignal os.Signal = os.Kill
+
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+func setProcessGroup(cmd *exec.Cmd) (restore func()) {
+	// not supported (for now?)
+	return func() {}
+}
+
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	// Windows can't deliver anything but a kill to another process
+	return cmd.Process.Kill()
+}
+
+func forwardSignal(cmd *exec.Cmd, sig os.Signal) error {
+	// the console already delivers Ctrl-C to every process attached to it
+	return nil
+}
+
+func TerminatingSignal(state *os.ProcessState) (int, bool) {
+	return 0, false
+}
+
+// Detach makes cmd start without a console, so that it keeps running after
+// the console that started it is closed.
+func Detach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.DETACHED_PROCESS | windows.CREATE_NEW_PROCESS_GROUP
+}
@@ -0,0 +1,5 @@
+package runner
+
+import "errors"
+
+var ErrPTYNotSupported = errors.New("pseudo-terminals are only supported on Linux and macOS")
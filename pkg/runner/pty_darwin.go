@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ioctlReadTermios  = unix.TIOCGETA
+	ioctlWriteTermios = unix.TIOCSETA
+)
+
+// openPTY returns a new pseudo-terminal master and the path of its slave,
+// the way grantpt, unlockpt and ptsname do it.
+func openPTY() (*os.File, string, error) {
+	master, err := openPTYMaster()
+	if err != nil {
+		return nil, "", err
+	}
+	var name [128]byte
+	rc, err := master.SyscallConn()
+	if err == nil {
+		ctlErr := rc.Control(func(fd uintptr) {
+			if err = unix.IoctlSetInt(int(fd), unix.TIOCPTYGRANT, 0); err != nil {
+				return
+			}
+			if err = unix.IoctlSetInt(int(fd), unix.TIOCPTYUNLK, 0); err != nil {
+				return
+			}
+			if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, unix.TIOCPTYGNAME, uintptr(unsafe.Pointer(&name[0]))); errno != 0 {
+				err = errno
+			}
+		})
+		if err == nil {
+			err = ctlErr
+		}
+	}
+	if err != nil {
+		master.Close()
+		return nil, "", err
+	}
+	return master, strings.TrimRight(string(name[:]), "\x00"), nil
+}
@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ioctlReadTermios  = unix.TCGETS
+	ioctlWriteTermios = unix.TCSETS
+)
+
+// openPTY returns a new pseudo-terminal master and the path of its slave.
+func openPTY() (*os.File, string, error) {
+	master, err := openPTYMaster()
+	if err != nil {
+		return nil, "", err
+	}
+	var n uint32
+	rc, err := master.SyscallConn()
+	if err == nil {
+		ctlErr := rc.Control(func(fd uintptr) {
+			if err = unix.IoctlSetPointerInt(int(fd), unix.TIOCSPTLCK, 0); err != nil {
+				return
+			}
+			n, err = unix.IoctlGetUint32(int(fd), unix.TIOCGPTN)
+		})
+		if err == nil {
+			err = ctlErr
+		}
+	}
+	if err != nil {
+		master.Close()
+		return nil, "", err
+	}
+	return master, fmt.Sprintf("/dev/pts/%d", n), nil
+}
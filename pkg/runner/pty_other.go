@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+
+package runner
+
+import "os/exec"
+
+const PTYSupported = false
+
+type pty struct{}
+
+func attachPTY(cmd *exec.Cmd) (*pty, error) {
+	// not supported (for now?)
+	return nil, ErrPTYNotSupported
+}
+
+func (p *pty) start() {}
+
+func (p *pty) close() {}
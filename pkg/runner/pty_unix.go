@@ -0,0 +1,230 @@
+//go:build linux || darwin
+
+package runner
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// PTYSupported reports whether Options.PTY works on this platform.
+const PTYSupported = true
+
+// ptyDrainTimeout bounds how long to wait for the rest of the output once the
+// command has exited: processes it left behind may keep the terminal open.
+const ptyDrainTimeout = time.Second
+
+// pty relays between a pseudo-terminal that the command runs in and n-cli's
+// own stdio.
+type pty struct {
+	master  *os.File
+	slave   *os.File
+	out     io.Writer
+	in      *os.File
+	started bool
+	copied  chan struct{}
+	winch   chan os.Signal
+	// restoreTerminal is the state of in before it was put in raw mode.
+	restoreTerminal *unix.Termios
+}
+
+// attachPTY makes cmd run in a new pseudo-terminal, as the session leader
+// with the terminal as its controlling terminal. Its output is copied to the
+// original cmd.Stdout (stderr included, since both are the terminal), and if
+// cmd.Stdin is a terminal, keystrokes are relayed to it.
+func attachPTY(cmd *exec.Cmd) (*pty, error) {
+	master, slavePath, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	slave, err := os.OpenFile(slavePath, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+	p := &pty{
+		master: master,
+		slave:  slave,
+		out:    cmd.Stdout,
+		copied: make(chan struct{}),
+		winch:  make(chan os.Signal, 1),
+	}
+	if p.out == nil {
+		p.out = io.Discard
+	}
+	// piped input is left alone, so that the command still sees its EOF
+	if in, ok := cmd.Stdin.(*os.File); ok && isTerminal(in) {
+		p.in = in
+		cmd.Stdin = slave
+	}
+	cmd.Stdout = slave
+	cmd.Stderr = slave
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// a new session is also a new process group, so signals still reach
+	// everything the command starts
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	// Ctty is a descriptor in the child; stdout is always the terminal
+	cmd.SysProcAttr.Ctty = 1
+	p.resize()
+	return p, nil
+}
+
+// start begins relaying, once the command is running.
+func (p *pty) start() {
+	p.started = true
+	// the command has its own copy; ours would keep the terminal open
+	p.slave.Close()
+	go func() {
+		defer close(p.copied)
+		// reads fail with EIO once the command and its children are gone
+		_, _ = io.Copy(p.out, p.master)
+	}()
+	if p.in != nil {
+		p.makeRaw()
+		relayInput(p.in, p.master)
+	}
+	signal.Notify(p.winch, syscall.SIGWINCH)
+	go func() {
+		for range p.winch {
+			p.resize()
+		}
+	}()
+}
+
+// close waits for the rest of the output and gives the terminal back.
+func (p *pty) close() {
+	signal.Stop(p.winch)
+	close(p.winch)
+	if p.started {
+		select {
+		case <-p.copied:
+		case <-time.After(ptyDrainTimeout):
+		}
+	} else {
+		p.slave.Close()
+	}
+	if p.in != nil {
+		relayInput(p.in, nil)
+	}
+	p.master.Close()
+	if p.restoreTerminal != nil {
+		_ = unix.IoctlSetTermios(int(p.in.Fd()), ioctlWriteTermios, p.restoreTerminal)
+	}
+}
+
+// inputRelay copies keystrokes to the pseudo-terminal of the current attempt.
+// There is only one per input, since a read from a terminal cannot be
+// interrupted: a relay per pty would be left behind when its pty is closed,
+// and steal the next keystroke from the retry.
+type inputRelay struct {
+	mu sync.Mutex
+	to *os.File
+}
+
+var (
+	inputRelaysMu sync.Mutex
+	inputRelays   = map[*os.File]*inputRelay{}
+)
+
+// relayInput sends what is typed into in to master from now on, or drops it
+// if master is nil.
+func relayInput(in, master *os.File) {
+	inputRelaysMu.Lock()
+	r, ok := inputRelays[in]
+	if !ok {
+		r = &inputRelay{}
+		inputRelays[in] = r
+		go r.run(in)
+	}
+	inputRelaysMu.Unlock()
+	r.mu.Lock()
+	r.to = master
+	r.mu.Unlock()
+}
+
+func (r *inputRelay) run(in *os.File) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			r.mu.Lock()
+			to := r.to
+			r.mu.Unlock()
+			// a full terminal must not hold up close, so write without the lock
+			if to != nil {
+				_, _ = to.Write(buf[:n])
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// makeRaw passes every keystroke, including Ctrl-C, straight to the command;
+// the pseudo-terminal does the line editing and echo instead. Output
+// processing stays on, so that n-cli's own messages still start on a new line.
+func (p *pty) makeRaw() {
+	fd := int(p.in.Fd())
+	termios, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return
+	}
+	saved := *termios
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB
+	termios.Cflag |= unix.CS8
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, termios); err == nil {
+		p.restoreTerminal = &saved
+	}
+}
+
+// resize gives the pseudo-terminal the size of n-cli's terminal, or 80x24 if
+// n-cli has none.
+func (p *pty) resize() {
+	size := &unix.Winsize{Row: 24, Col: 80}
+	for _, f := range []*os.File{os.Stdout, os.Stderr, os.Stdin} {
+		if ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ); err == nil && ws.Row > 0 && ws.Col > 0 {
+			size = ws
+			break
+		}
+	}
+	// Fd() would switch the master to blocking mode, which close relies on
+	// not being the case
+	rc, err := p.master.SyscallConn()
+	if err != nil {
+		return
+	}
+	_ = rc.Control(func(fd uintptr) {
+		_ = unix.IoctlSetWinsize(int(fd), unix.TIOCSWINSZ, size)
+	})
+}
+
+func isTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), ioctlReadTermios)
+	return err == nil
+}
+
+// openPTYMaster opens a new pseudo-terminal master, without making it
+// n-cli's controlling terminal.
+func openPTYMaster() (*os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrPTYNotSupported
+	}
+	return master, err
+}
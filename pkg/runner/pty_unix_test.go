@@ -0,0 +1,84 @@
+//go:build linux || darwin
+
+package runner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPTY(t *testing.T) {
+	t.Run("the command sees a terminal", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := exec.Command("sh", "-c", "test -t 1 && echo stdout; test -t 2 && echo stderr >&2; stty size </dev/tty")
+		cmd.Stdout = &out
+		result := Run(context.Background(), cmd, Options{PTY: true})
+		require.NoError(t, result.Err)
+		assert.Equal(t, 0, ExitCode(cmd, result))
+		// it is the controlling terminal; without a terminal of our own, it is 80x24
+		assert.Equal(t, "stdout\r\nstderr\r\n24 80\r\n", out.String())
+	})
+
+	t.Run("piped input is passed through", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := exec.Command("sh", "-c", "cat; test -t 0 || echo not a terminal")
+		cmd.Stdin = bytes.NewBufferString("hello\n")
+		cmd.Stdout = &out
+		result := Run(context.Background(), cmd, Options{PTY: true})
+		require.NoError(t, result.Err)
+		assert.Equal(t, "hello\r\nnot a terminal\r\n", out.String())
+	})
+
+	t.Run("propagates the command's exit code", func(t *testing.T) {
+		cmd := exec.Command("sh", "-c", "exit 3")
+		result := Run(context.Background(), cmd, Options{PTY: true})
+		assert.Equal(t, 3, ExitCode(cmd, result))
+	})
+
+	t.Run("stops the whole session on timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		cmd := exec.Command("sh", "-c", "sleep 10 & wait")
+		startedFrom := time.Now()
+		result := Run(ctx, cmd, Options{PTY: true, KillAfter: time.Second})
+		assert.True(t, result.TimedOut)
+		assert.Equal(t, ExitCodeTimedOut, ExitCode(cmd, result))
+		assert.Less(t, time.Since(startedFrom), 2*time.Second)
+	})
+
+	t.Run("keystrokes go to the latest attempt", func(t *testing.T) {
+		// a terminal to type into, standing in for n-cli's own
+		terminal, slavePath, err := openPTY()
+		require.NoError(t, err)
+		defer terminal.Close()
+		keyboard, err := os.OpenFile(slavePath, os.O_RDWR|syscall.O_NOCTTY, 0)
+		require.NoError(t, err)
+		defer keyboard.Close()
+
+		first := exec.Command("true")
+		first.Stdin = keyboard
+		Run(context.Background(), first, Options{PTY: true})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		var out bytes.Buffer
+		retry := exec.Command("sh", "-c", "read line && echo got $line")
+		retry.Stdin = keyboard
+		retry.Stdout = &out
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			_, _ = terminal.WriteString("hello\r")
+		}()
+		result := Run(ctx, retry, Options{PTY: true})
+		assert.False(t, result.TimedOut, "the first attempt's relay took the input")
+		assert.Contains(t, out.String(), "got hello")
+	})
+}
@@ -0,0 +1,133 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+)
+
+var ErrCannotQuote = errors.New("cmd.exe cannot be passed separate arguments safely, pass the command line as a single argument instead")
+
+// ResolveShell picks the shell used by shell mode: the configured shell if
+// any, then $SHELL (%COMSPEC% on Windows), then the platform default.
+func ResolveShell(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	if runtime.GOOS == "windows" {
+		if comspec := os.Getenv("COMSPEC"); comspec != "" {
+			return comspec
+		}
+		return "cmd.exe"
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// shellName returns the name of shell without its directory and .exe, e.g.
+// "cmd" or "bash".
+func shellName(shell string) string {
+	// accept both separators so Windows paths resolve on every platform
+	name := strings.ToLower(shell[strings.LastIndexAny(shell, `/\`)+1:])
+	return strings.TrimSuffix(name, ".exe")
+}
+
+// ShellArgs returns the argv that makes shell interpret commandLine.
+func ShellArgs(shell, commandLine string) []string {
+	switch shellName(shell) {
+	case "cmd":
+		return []string{shell, "/C", commandLine}
+	case "powershell", "pwsh":
+		return []string{shell, "-NoProfile", "-Command", commandLine}
+	default:
+		return []string{shell, "-c", commandLine}
+	}
+}
+
+// CommandLine turns run arguments into a single line, quoted for a POSIX
+// shell. It names the command in notifications and the history; use
+// ShellCommandLine for the line that a shell runs.
+func CommandLine(args []string) string {
+	return joinQuoted(args, quote)
+}
+
+// ShellCommandLine turns run arguments into the line handed to shell. A single
+// argument is used verbatim, so that operators such as && keep working.
+// Several arguments are quoted for shell, so that each one reaches the command
+// unchanged; cmd.exe has no such quoting, so it only takes a single argument.
+func ShellCommandLine(shell string, args []string) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	switch shellName(shell) {
+	case "cmd":
+		return "", ErrCannotQuote
+	case "powershell", "pwsh":
+		// a quoted command name is a string to PowerShell, unless it is invoked with &
+		return "& " + joinQuoted(args, quotePowerShell), nil
+	default:
+		return joinQuoted(args, quote), nil
+	}
+}
+
+func joinQuoted(args []string, quote func(string) string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// quote quotes s for a POSIX shell, leaving it as is if that is safe.
+func quote(s string) string {
+	if s != "" && !strings.ContainsFunc(s, unsafeIn("_@%+=:,./-")) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quotePowerShell quotes s for PowerShell, leaving it as is if that is safe.
+func quotePowerShell(s string) string {
+	if s != "" && !strings.ContainsFunc(s, unsafeIn(`_:./\-`)) {
+		return s
+	}
+	// typographic quotes delimit strings too, and are doubled the same way
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		if strings.ContainsRune("'\u2018\u2019\u201a\u201b", r) {
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// unsafeIn reports runes other than letters, digits and safe.
+func unsafeIn(safe string) func(rune) bool {
+	return func(r rune) bool {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+			return false
+		}
+		return !strings.ContainsRune(safe, r)
+	}
+}
+
+// TrimSeparator drops a -- right after the command. n-cli run used to need
+// one before the command's flags, as in n-cli run mycommand -- --flag, and
+// now that everything after the command belongs to it, the -- would reach
+// the command.
+func TrimSeparator(args []string) []string {
+	if len(args) > 1 && args[1] == "--" {
+		return append(args[:1:1], args[2:]...)
+	}
+	return args
+}
@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveShell(t *testing.T) {
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	t.Setenv("COMSPEC", `C:\Windows\System32\cmd.exe`)
+
+	assert.Equal(t, "/usr/local/bin/fish", ResolveShell("/usr/local/bin/fish"))
+	if runtime.GOOS == "windows" {
+		assert.Equal(t, `C:\Windows\System32\cmd.exe`, ResolveShell(""))
+	} else {
+		assert.Equal(t, "/usr/bin/zsh", ResolveShell(""))
+	}
+
+	t.Setenv("SHELL", "")
+	t.Setenv("COMSPEC", "")
+	if runtime.GOOS == "windows" {
+		assert.Equal(t, "cmd.exe", ResolveShell(""))
+	} else {
+		assert.Equal(t, "/bin/sh", ResolveShell(""))
+	}
+}
+
+func TestShellArgs(t *testing.T) {
+	testCases := []struct {
+		shell    string
+		expected []string
+	}{
+		{shell: "/bin/bash", expected: []string{"/bin/bash", "-c", "make && make test"}},
+		{shell: "/usr/bin/fish", expected: []string{"/usr/bin/fish", "-c", "make && make test"}},
+		{shell: `C:\Windows\System32\cmd.exe`, expected: []string{`C:\Windows\System32\cmd.exe`, "/C", "make && make test"}},
+		{shell: "pwsh", expected: []string{"pwsh", "-NoProfile", "-Command", "make && make test"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.shell, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ShellArgs(tc.shell, "make && make test"))
+		})
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	assert.Equal(t, "make && make test", CommandLine([]string{"make && make test"}))
+	assert.Equal(t, "ls -la", CommandLine([]string{"ls", "-la"}))
+	assert.Equal(t, `printf '%s\n' 'a  b' 'it'\''s' ''`, CommandLine([]string{"printf", `%s\n`, "a  b", "it's", ""}))
+}
+
+func TestShellCommandLine(t *testing.T) {
+	for _, shell := range []string{"/bin/bash", "pwsh", `C:\Windows\System32\cmd.exe`} {
+		line, err := ShellCommandLine(shell, []string{"make && make test"})
+		assert.NoError(t, err, shell)
+		assert.Equal(t, "make && make test", line, shell)
+	}
+
+	line, err := ShellCommandLine("/usr/bin/zsh", []string{"echo", "it's", "$HOME"})
+	assert.NoError(t, err)
+	assert.Equal(t, `echo 'it'\''s' '$HOME'`, line)
+
+	line, err = ShellCommandLine("powershell.exe", []string{`C:\Program Files\app.exe`, "-Name", "it's", "$HOME", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, `& 'C:\Program Files\app.exe' -Name 'it''s' '$HOME' ''`, line)
+
+	_, err = ShellCommandLine("cmd.exe", []string{"echo", "%PATH%"})
+	assert.ErrorIs(t, err, ErrCannotQuote)
+}
+
+func TestTrimSeparator(t *testing.T) {
+	// the usage documented before everything after the command went to it
+	assert.Equal(t, []string{"mycommand", "--flag1=true", "--flag2"}, TrimSeparator([]string{"mycommand", "--", "--flag1=true", "--flag2"}))
+	assert.Equal(t, []string{"npm", "run", "build", "--", "--watch"}, TrimSeparator([]string{"npm", "run", "build", "--", "--watch"}))
+	assert.Equal(t, []string{"mycommand"}, TrimSeparator([]string{"mycommand", "--"}))
+	assert.Equal(t, []string{"--"}, TrimSeparator([]string{"--"}))
+}
+
+func TestCommandLineRoundTrip(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	args := []string{"printf", `%s\n`, "a  b", "it's", `"quoted"`, "$HOME", "*", "a\nb", "", "--flag=x y"}
+	out, err := exec.Command("/bin/sh", "-c", CommandLine(args)).Output()
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Join(args[2:], "\n")+"\n", string(out))
+}
@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownSignal = errors.New("unknown signal")
+
+// ParseSignal accepts signal names with or without the SIG prefix (TERM,
+// SIGINT) as well as signal numbers.
+func ParseSignal(s string) (os.Signal, error) {
+	name := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "SIG")
+	if sig, ok := signalsByName[name]; ok {
+		return sig, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		for _, sig := range signalsByName {
+			if signalNumber(sig) == n {
+				return sig, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownSignal, s)
+}
+
+// SignalName returns the conventional name of sig, e.g. SIGINT.
+func SignalName(sig os.Signal) string {
+	for name, s := range signalsByName {
+		if s == sig {
+			return "SIG" + name
+		}
+	}
+	return sig.String()
+}
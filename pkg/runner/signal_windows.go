@@ -0,0 +1,22 @@
+//go:build windows
+
+package runner
+
+import (
+	"os"
+	"syscall"
+)
+
+// Windows processes can only be killed, so every signal ends up as a kill; the
+// names are still accepted so that the same flags work everywhere.
+var signalsByName = map[string]os.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
+
+func signalNumber(sig os.Signal) int {
+	return int(sig.(syscall.Signal))
+}
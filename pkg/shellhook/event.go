@@ -0,0 +1,103 @@
+// Package shellhook notifies about slow commands typed into an interactive
+// shell, without prefixing them with n-cli run.
+package shellhook
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const DefaultThreshold = 30 * time.Second
+
+// DefaultIgnore lists interactive programs that take long because someone is
+// using them, not because they are working.
+var DefaultIgnore = []string{
+	"vi", "vim", "nvim", "nano", "emacs", "less", "more", "man", "ssh", "mosh",
+	"top", "htop", "btop", "tmux", "screen", "watch", "fg", "n-cli",
+}
+
+// exitCodeInterrupted means the command did not finish on its own but was
+// stopped with Ctrl-C. exitCodeSuspended, for Ctrl-Z, depends on the platform.
+const exitCodeInterrupted = 130
+
+// Event is a command that finished in an interactive shell.
+type Event struct {
+	Command  string
+	ExitCode int
+	Duration time.Duration
+	Cwd      string
+}
+
+type Options struct {
+	Threshold time.Duration
+	// Ignore lists programs, e.g. vim, or command prefixes, e.g. "git log",
+	// that are never notified about.
+	Ignore []string
+	// Cooldown is the minimum time between two notifications.
+	Cooldown time.Duration
+}
+
+// ShouldNotify reports whether e is worth a notification, given when the last
+// one was sent.
+func ShouldNotify(e Event, opts Options, lastNotified, now time.Time) bool {
+	if e.Duration < opts.Threshold || strings.TrimSpace(e.Command) == "" {
+		return false
+	}
+	if e.ExitCode == exitCodeInterrupted || e.ExitCode == exitCodeSuspended {
+		return false
+	}
+	if Ignored(e.Command, opts.Ignore) {
+		return false
+	}
+	return opts.Cooldown <= 0 || lastNotified.IsZero() || now.Sub(lastNotified) >= opts.Cooldown
+}
+
+// wrappers run the command that follows them.
+var wrappers = []string{"sudo", "time", "command", "exec", "nohup", "env", "nice"}
+
+// Ignored reports whether command runs one of the programs in ignore. Entries
+// with a space match the start of the command instead, e.g. "git log".
+func Ignored(command string, ignore []string) bool {
+	words := strings.Fields(command)
+	for len(words) > 0 && (slices.Contains(wrappers, words[0]) || isAssignment(words[0])) {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return false
+	}
+	program := filepath.Base(words[0])
+	line := strings.Join(append([]string{program}, words[1:]...), " ")
+	for _, entry := range ignore {
+		entry = strings.Join(strings.Fields(entry), " ")
+		if entry == program || (strings.Contains(entry, " ") && (line == entry || strings.HasPrefix(line, entry+" "))) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAssignment reports whether word sets an environment variable, as in
+// FOO=bar make.
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	return ok && name != "" && !strings.ContainsAny(name, "/-.")
+}
+
+// FormatMessage is the notification for e.
+func FormatMessage(e Event) string {
+	status := "COMPLETE"
+	if e.ExitCode != 0 {
+		status = fmt.Sprintf("FAILED (exit code %d)", e.ExitCode)
+	}
+	lines := []string{
+		fmt.Sprintf("Command `%s` %s.", e.Command, status),
+		fmt.Sprintf("Elapsed: %s", e.Duration.Round(time.Second)),
+	}
+	if e.Cwd != "" {
+		lines = append(lines, fmt.Sprintf("Directory: %s", e.Cwd))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,57 @@
+package shellhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldNotify(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	opts := Options{Threshold: 30 * time.Second, Ignore: DefaultIgnore, Cooldown: 5 * time.Minute}
+	build := Event{Command: "make build", Duration: 4 * time.Minute}
+
+	assert.True(t, ShouldNotify(build, opts, time.Time{}, now))
+	assert.True(t, ShouldNotify(build, opts, now.Add(-5*time.Minute), now))
+	assert.False(t, ShouldNotify(build, opts, now.Add(-time.Minute), now), "cooldown")
+	assert.True(t, ShouldNotify(build, Options{Threshold: opts.Threshold}, now.Add(-time.Minute), now), "no cooldown")
+
+	assert.False(t, ShouldNotify(Event{Command: "make build", Duration: 10 * time.Second}, opts, time.Time{}, now), "quick")
+	assert.False(t, ShouldNotify(Event{Command: "vim main.go", Duration: time.Hour}, opts, time.Time{}, now), "ignored")
+	assert.False(t, ShouldNotify(Event{Command: "make build", Duration: time.Hour, ExitCode: 130}, opts, time.Time{}, now), "Ctrl-C")
+	assert.False(t, ShouldNotify(Event{Command: "make build", Duration: time.Hour, ExitCode: exitCodeSuspended}, opts, time.Time{}, now), "Ctrl-Z")
+	assert.False(t, ShouldNotify(Event{Command: "  ", Duration: time.Hour}, opts, time.Time{}, now), "empty")
+	assert.True(t, ShouldNotify(Event{Command: "make test", Duration: time.Hour, ExitCode: 2}, opts, time.Time{}, now), "failed")
+}
+
+func TestIgnored(t *testing.T) {
+	ignore := []string{"vim", "ssh", "git  log"}
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"vim main.go", true},
+		{"/usr/bin/vim", true},
+		{"sudo vim /etc/hosts", true},
+		{"EDITOR=x TERM=y ssh host", true},
+		{"git log --oneline", true},
+		{"git log", true},
+		{"git logs", false},
+		{"git push", false},
+		{"vimdiff a b", false},
+		{"make vim", false},
+		{"sudo", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Ignored(tt.command, ignore), tt.command)
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	assert.Equal(t, "Command `make build` COMPLETE.\nElapsed: 4m12s\nDirectory: ~/src/app",
+		FormatMessage(Event{Command: "make build", Duration: 4*time.Minute + 12400*time.Millisecond, Cwd: "~/src/app"}))
+	assert.Equal(t, "Command `make test` FAILED (exit code 2).\nElapsed: 45s",
+		FormatMessage(Event{Command: "make test", ExitCode: 2, Duration: 45 * time.Second}))
+}
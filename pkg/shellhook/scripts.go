@@ -0,0 +1,134 @@
+package shellhook
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnsupportedShell = errors.New("unsupported shell (expected bash, zsh or fish)")
+
+// Shells lists the shells that Script supports.
+var Shells = []string{"bash", "zsh", "fish"}
+
+// Script returns the hook code for shell. It times every interactive command
+// and runs n-cli shell-event in the background for the ones that take at
+// least threshold, so that short commands cost no more than some arithmetic.
+func Script(shell string, threshold time.Duration) (string, error) {
+	var script string
+	switch shell {
+	case "bash":
+		script = bashScript
+	case "zsh":
+		script = zshScript
+	case "fish":
+		script = fishScript
+	default:
+		return "", ErrUnsupportedShell
+	}
+	return strings.ReplaceAll(script, "__N_CLI_THRESHOLD_MS__", strconv.FormatInt(threshold.Milliseconds(), 10)), nil
+}
+
+// bash has no preexec hook: the DEBUG trap stands in for it, armed by the
+// last entry of PROMPT_COMMAND so that it only fires for the first command
+// typed at the prompt. A DEBUG trap that is already set keeps running after
+// ours, and with bash-preexec loaded, its hook arrays are used instead.
+const bashScript = `# n-cli shell integration, see n-cli shell-init --help
+__n_cli_threshold_ms=__N_CLI_THRESHOLD_MS__
+__n_cli_now_us() {
+  __n_cli_now=${EPOCHREALTIME/[.,]/}
+  : "${__n_cli_now:=$((SECONDS * 1000000))}"
+}
+__n_cli_preexec() {
+  [ -n "$__n_cli_armed" ] && [ -z "$COMP_LINE" ] || return 0
+  __n_cli_armed=
+  local line
+  line=$(HISTTIMEFORMAT= builtin history 1 2>/dev/null)
+  if [[ $line =~ ^[[:space:]]*[0-9]+\*?[[:space:]]+(.*)$ ]]; then
+    __n_cli_cmd=${BASH_REMATCH[1]}
+  else
+    __n_cli_cmd=$BASH_COMMAND
+  fi
+  __n_cli_now_us
+  __n_cli_start=$__n_cli_now
+}
+__n_cli_precmd() {
+  local exit_code=$?
+  if [ -n "$__n_cli_start" ]; then
+    __n_cli_now_us
+    local ms=$(( (__n_cli_now - __n_cli_start) / 1000 ))
+    __n_cli_start=
+    if (( ms >= __n_cli_threshold_ms )); then
+      (command n-cli shell-event --exit-code "$exit_code" --duration "${ms}ms" -- "$__n_cli_cmd" >/dev/null 2>&1 &)
+    fi
+  fi
+  # keep $? for the rest of PROMPT_COMMAND and the prompt
+  return "$exit_code"
+}
+__n_cli_arm() {
+  __n_cli_armed=1
+}
+__n_cli_bp_preexec() {
+  __n_cli_cmd=$1
+  __n_cli_now_us
+  __n_cli_start=$__n_cli_now
+}
+__n_cli_return() {
+  return "$1"
+}
+__n_cli_debug() {
+  local exit_code=$?
+  __n_cli_preexec
+  [ -n "$__n_cli_prev_debug" ] || return 0
+  # the previous trap sees the same $? as it did before
+  __n_cli_return "$exit_code"
+  eval "$__n_cli_prev_debug"
+}
+__n_cli_save_debug() {
+  [ "$2" = __n_cli_debug ] || __n_cli_prev_debug=$2
+}
+if [[ -n ${bash_preexec_imported:-${__bp_imported:-}} ]]; then
+  [[ " ${preexec_functions[*]} " == *" __n_cli_bp_preexec "* ]] || preexec_functions+=(__n_cli_bp_preexec)
+  [[ " ${precmd_functions[*]} " == *" __n_cli_precmd "* ]] || precmd_functions+=(__n_cli_precmd)
+else
+  if [[ $PROMPT_COMMAND != *__n_cli_precmd* ]]; then
+    PROMPT_COMMAND=$'__n_cli_precmd\n'"${PROMPT_COMMAND}"$'\n__n_cli_arm'
+  fi
+  # only visible at the top level, as with eval "$(n-cli shell-init bash)"
+  __n_cli_debug_trap=$(trap -p DEBUG)
+  [ -z "$__n_cli_debug_trap" ] || eval "__n_cli_save_debug ${__n_cli_debug_trap#trap }"
+  unset __n_cli_debug_trap
+  trap '__n_cli_debug' DEBUG
+fi
+`
+
+const zshScript = `# n-cli shell integration, see n-cli shell-init --help
+zmodload zsh/datetime
+typeset -g __n_cli_threshold_ms=__N_CLI_THRESHOLD_MS__
+__n_cli_preexec() {
+  __n_cli_cmd=$1
+  __n_cli_start=$EPOCHREALTIME
+}
+__n_cli_precmd() {
+  local exit_code=$?
+  [[ -n $__n_cli_start ]] || return 0
+  local -i ms=$(( (EPOCHREALTIME - __n_cli_start) * 1000 ))
+  __n_cli_start=
+  (( ms >= __n_cli_threshold_ms )) || return 0
+  command n-cli shell-event --exit-code $exit_code --duration ${ms}ms -- "$__n_cli_cmd" &>/dev/null &!
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec __n_cli_preexec
+add-zsh-hook precmd __n_cli_precmd
+`
+
+const fishScript = `# n-cli shell integration, see n-cli shell-init --help
+function __n_cli_postexec --on-event fish_postexec
+    set -l exit_code $status
+    set -l ms $CMD_DURATION
+    test -n "$ms"; and test "$ms" -ge __N_CLI_THRESHOLD_MS__; or return 0
+    command n-cli shell-event --exit-code $exit_code --duration {$ms}ms -- $argv[1] >/dev/null 2>&1 &
+    disown 2>/dev/null
+end
+`
@@ -0,0 +1,113 @@
+package shellhook
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScript(t *testing.T) {
+	for _, shell := range Shells {
+		script, err := Script(shell, 45*time.Second)
+		require.NoError(t, err, shell)
+		assert.Contains(t, script, "45000", shell)
+		assert.NotContains(t, script, "__N_CLI_THRESHOLD_MS__", shell)
+		assert.Contains(t, script, "n-cli shell-event", shell)
+	}
+	_, err := Script("tcsh", time.Second)
+	assert.ErrorIs(t, err, ErrUnsupportedShell)
+}
+
+func TestBashScript(t *testing.T) {
+	events, out := runBashHook(t, "",
+		"echo quick",
+		"sleep 0.3 && echo slow",
+		"",
+		`FOO=1 sh -c "sleep 0.2; exit 3"`,
+		"sleep 0.2 | cat",
+	)
+	assert.Contains(t, out, "status=3", "$? is kept for the rest of PROMPT_COMMAND")
+
+	lines := waitForEvents(t, events, 3)
+	assert.Regexp(t, `^shell-event\|--exit-code\|0\|--duration\|\d+ms\|--\|sleep 0.3 && echo slow\|$`, lines[0])
+	assert.Regexp(t, `^shell-event\|--exit-code\|3\|--duration\|\d+ms\|--\|FOO=1 sh -c "sleep 0.2; exit 3"\|$`, lines[1])
+	assert.Regexp(t, `^shell-event\|--exit-code\|0\|--duration\|\d+ms\|--\|sleep 0.2 \| cat\|$`, lines[2])
+}
+
+func TestBashScriptKeepsDebugTrap(t *testing.T) {
+	events, out := runBashHook(t, `trap 'echo "debug: $BASH_COMMAND ($?)"' DEBUG`,
+		"false",
+		`eval "$(cat $HOOK)"`,
+		"sleep 0.3",
+	)
+	assert.Contains(t, out, "debug: false (0)")
+	assert.Contains(t, out, "debug: eval \"$(cat $HOOK)\" (1)", "the trap sees the same $? as before")
+	assert.Equal(t, 1, strings.Count(out, "debug: sleep 0.3 "), "loading the hook twice does not chain it to itself")
+
+	lines := waitForEvents(t, events, 1)
+	assert.Regexp(t, `^shell-event\|--exit-code\|0\|--duration\|\d+ms\|--\|sleep 0.3\|$`, lines[0])
+}
+
+func TestBashScriptWithBashPreexec(t *testing.T) {
+	// enough of bash-preexec for the hook to register with it
+	_, out := runBashHook(t, "bash_preexec_imported=defined; preexec_functions=(); precmd_functions=(other)",
+		`echo "preexec: ${preexec_functions[*]}"`,
+		`echo "precmd: ${precmd_functions[*]}"`,
+		`echo "trap: $(trap -p DEBUG)"`,
+		`eval "$(cat $HOOK)"`,
+		`echo "again: ${preexec_functions[*]} / ${precmd_functions[*]}"`,
+	)
+	assert.Contains(t, out, "preexec: __n_cli_bp_preexec\n")
+	assert.Contains(t, out, "precmd: other __n_cli_precmd\n")
+	assert.Contains(t, out, "trap: \n", "bash-preexec owns the DEBUG trap")
+	assert.Contains(t, out, "again: __n_cli_bp_preexec / other __n_cli_precmd\n")
+}
+
+// runBashHook runs lines in an interactive bash, after setup and loading the
+// hook ($HOOK) as shell-init tells you to, with a fake n-cli that records its
+// calls to events.
+func runBashHook(t *testing.T, setup string, lines ...string) (events, out string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	dir := t.TempDir()
+	events = filepath.Join(dir, "events")
+	// a fake n-cli that records how it was called
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "n-cli"), []byte("#!/bin/sh\nprintf '%s|' \"$@\" >> "+events+"\necho >> "+events+"\n"), 0o755))
+	script, err := Script("bash", 100*time.Millisecond)
+	require.NoError(t, err)
+	hook := filepath.Join(dir, "hook.bash")
+	require.NoError(t, os.WriteFile(hook, []byte(script), 0o644))
+
+	cmd := exec.Command(bash, "--norc", "-i")
+	cmd.Env = append(os.Environ(), "PATH="+dir+":"+os.Getenv("PATH"), "HISTFILE=/dev/null", "PROMPT_COMMAND=echo status=$?;", "HOOK="+hook)
+	input := append([]string{setup, `eval "$(cat $HOOK)"`}, lines...)
+	cmd.Stdin = strings.NewReader(strings.Join(append(input, "exit"), "\n") + "\n")
+	b, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(b))
+	return events, string(b)
+}
+
+// waitForEvents waits for the hook, which runs n-cli in the background.
+func waitForEvents(t *testing.T, events string, n int) []string {
+	t.Helper()
+	var lines []string
+	require.Eventually(t, func() bool {
+		b, _ := os.ReadFile(events)
+		lines = strings.Split(strings.TrimSpace(string(b)), "\n")
+		return len(lines) == n
+	}, 5*time.Second, 50*time.Millisecond)
+	return lines
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package shellhook
+
+import "syscall"
+
+// exitCodeSuspended is what the shell reports when Ctrl-Z suspends a command:
+// 148 on Linux, 146 on macOS and the BSDs.
+const exitCodeSuspended = 128 + int(syscall.SIGTSTP)
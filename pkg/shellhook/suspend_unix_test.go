@@ -0,0 +1,19 @@
+//go:build !windows
+
+package shellhook
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodeSuspended(t *testing.T) {
+	switch runtime.GOOS {
+	case "linux":
+		assert.Equal(t, 148, exitCodeSuspended)
+	case "darwin", "freebsd", "openbsd", "netbsd":
+		assert.Equal(t, 146, exitCodeSuspended)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build windows
+
+package shellhook
+
+// exitCodeSuspended is what the shell reports when Ctrl-Z suspends a command.
+// The bash of Git for Windows and Cygwin numbers SIGTSTP 18, as the BSDs do.
+const exitCodeSuspended = 128 + 18
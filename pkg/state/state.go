@@ -0,0 +1,120 @@
+// Package state keeps small pieces of state between n-cli invocations, such
+// as how many times in a row a cron job has failed.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidName = errors.New("invalid state name (expected letters, digits, '-' and '_')")
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Store holds one JSON file per name in Dir.
+type Store struct {
+	Dir string
+}
+
+// DefaultDir is ~/.n-cli/state.
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".n-cli", "state"), nil
+}
+
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// Load reads the state saved under name into v. Missing state leaves v
+// untouched.
+func (s *Store) Load(name string, v any) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Save writes v under name, replacing the file atomically so that readers
+// never see half of it.
+func (s *Store) Save(name string, v any) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes the state saved under name, if any.
+func (s *Store) Delete(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Names lists the names that have state saved, in alphabetical order.
+func (s *Store) Names() ([]string, error) {
+	files, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range files {
+		name, ok := strings.CutSuffix(f.Name(), ".json")
+		if ok && !f.IsDir() && validName.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *Store) path(name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", ErrInvalidName
+	}
+	return filepath.Join(s.Dir, name+".json"), nil
+}
@@ -0,0 +1,51 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type counter struct {
+	Count int `json:"count"`
+}
+
+func TestStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	s := NewStore(dir)
+
+	names, err := s.Names()
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	c := counter{Count: 7}
+	require.NoError(t, s.Load("cron-backup", &c))
+	assert.Equal(t, 7, c.Count, "missing state leaves the value untouched")
+
+	require.NoError(t, s.Save("cron-backup", counter{Count: 3}))
+	c = counter{}
+	require.NoError(t, s.Load("cron-backup", &c))
+	assert.Equal(t, 3, c.Count)
+
+	require.NoError(t, s.Save("cron-a", counter{}))
+	names, err = s.Names()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cron-a", "cron-backup"}, names, "no temporary files are left behind")
+	require.NoError(t, s.Delete("cron-a"))
+
+	require.NoError(t, s.Delete("cron-backup"))
+	require.NoError(t, s.Delete("cron-backup"))
+	_, err = os.Stat(filepath.Join(dir, "cron-backup.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestStoreInvalidName(t *testing.T) {
+	s := NewStore(t.TempDir())
+	for _, name := range []string{"", "../x", "a/b", "a.b"} {
+		assert.ErrorIs(t, s.Save(name, counter{}), ErrInvalidName, name)
+		assert.ErrorIs(t, s.Load(name, &counter{}), ErrInvalidName, name)
+	}
+}
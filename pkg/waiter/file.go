@@ -0,0 +1,91 @@
+package waiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+var (
+	ErrFileMissing   = errors.New("file does not exist yet")
+	ErrFileUnchanged = errors.New("file has not changed")
+	ErrFileExists    = errors.New("file still exists")
+)
+
+type fileCondition struct {
+	path    string
+	existed bool
+	modTime time.Time
+	size    int64
+}
+
+// File is met once path exists. If it already exists, it is met once the file
+// changes instead.
+func File(path string) Condition {
+	c := &fileCondition{path: path}
+	if info, err := os.Stat(path); err == nil {
+		c.existed = true
+		c.modTime = info.ModTime()
+		c.size = info.Size()
+	}
+	return c
+}
+
+func (c *fileCondition) Check(ctx context.Context) (bool, error) {
+	info, err := os.Stat(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, ErrFileMissing
+	}
+	if err != nil {
+		return false, err
+	}
+	if c.existed && info.ModTime().Equal(c.modTime) && info.Size() == c.size {
+		return false, ErrFileUnchanged
+	}
+	return true, nil
+}
+
+func (c *fileCondition) Describe() string {
+	if c.existed {
+		return fmt.Sprintf("%s to change", c.path)
+	}
+	return fmt.Sprintf("%s to exist", c.path)
+}
+
+func (c *fileCondition) ReadyMessage() string {
+	if c.existed {
+		return fmt.Sprintf("%s has changed.", c.path)
+	}
+	return fmt.Sprintf("%s has been created.", c.path)
+}
+
+type fileAbsentCondition struct {
+	path string
+}
+
+// FileAbsent is met once path no longer exists, e.g. a lock file.
+func FileAbsent(path string) Condition {
+	return &fileAbsentCondition{path: path}
+}
+
+func (c *fileAbsentCondition) Check(ctx context.Context) (bool, error) {
+	_, err := os.Stat(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, ErrFileExists
+}
+
+func (c *fileAbsentCondition) Describe() string {
+	return fmt.Sprintf("%s to be removed", c.path)
+}
+
+func (c *fileAbsentCondition) ReadyMessage() string {
+	return fmt.Sprintf("%s is gone.", c.path)
+}
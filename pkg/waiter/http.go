@@ -0,0 +1,73 @@
+package waiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	restyutils "github.com/lba-studio/n-cli/pkg/resty_utils"
+)
+
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+// httpRequestTimeout keeps an unresponsive server from stalling a check for too long.
+const httpRequestTimeout = 10 * time.Second
+
+type HTTPOptions struct {
+	// StatusCodes lists the accepted status codes. Defaults to any 2xx.
+	StatusCodes []int
+	// BodyRegex must match the response body, if set.
+	BodyRegex *regexp.Regexp
+}
+
+type httpCondition struct {
+	url      string
+	opts     HTTPOptions
+	restyCli *resty.Client
+	status   int
+}
+
+// HTTP is met once a GET request to url gets the expected response.
+func HTTP(url string, opts HTTPOptions) Condition {
+	return &httpCondition{
+		url:  url,
+		opts: opts,
+		restyCli: resty.New().
+			SetLogger(&restyutils.RestyLogger{}).
+			SetTimeout(httpRequestTimeout),
+	}
+}
+
+func (c *httpCondition) Check(ctx context.Context) (bool, error) {
+	resp, err := c.restyCli.R().SetContext(ctx).Get(c.url)
+	if err != nil {
+		return false, err
+	}
+	if !c.statusOK(resp.StatusCode()) {
+		return false, fmt.Errorf("%w: status %s", ErrUnexpectedResponse, resp.Status())
+	}
+	if c.opts.BodyRegex != nil && !c.opts.BodyRegex.Match(resp.Body()) {
+		return false, fmt.Errorf("%w: body does not match %s", ErrUnexpectedResponse, c.opts.BodyRegex.String())
+	}
+	c.status = resp.StatusCode()
+	return true, nil
+}
+
+func (c *httpCondition) statusOK(status int) bool {
+	if len(c.opts.StatusCodes) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(c.opts.StatusCodes, status)
+}
+
+func (c *httpCondition) Describe() string {
+	return fmt.Sprintf("%s to respond", c.url)
+}
+
+func (c *httpCondition) ReadyMessage() string {
+	return fmt.Sprintf("%s is up (status %d).", c.url, c.status)
+}
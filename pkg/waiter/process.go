@@ -0,0 +1,60 @@
+package waiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lba-studio/n-cli/pkg/formatter"
+	"github.com/lba-studio/n-cli/pkg/monitor"
+)
+
+var ErrProcessRunning = errors.New("process is still running")
+
+type processCondition struct {
+	// last is the last snapshot taken while the process was still running
+	last     *monitor.Process
+	exitedAt time.Time
+}
+
+// ProcessExit is met once p exits.
+func ProcessExit(p *monitor.Process) Condition {
+	return &processCondition{last: p}
+}
+
+func (c *processCondition) Check(ctx context.Context) (bool, error) {
+	current, err := monitor.GetProcess(c.last.Pid)
+	// a different start time means the pid has been reused
+	if err != nil || current.Exited() || !current.StartedAt.Equal(c.last.StartedAt) {
+		c.exitedAt = time.Now()
+		return true, nil
+	}
+	c.last = current
+	return false, ErrProcessRunning
+}
+
+func (c *processCondition) Describe() string {
+	return fmt.Sprintf("pid %d (%s) to exit", c.last.Pid, ProcessCommand(c.last))
+}
+
+func (c *processCondition) ReadyMessage() string {
+	infoStrings := []string{
+		fmt.Sprintf("Process `%s` (pid %d) EXITED.", ProcessCommand(c.last), c.last.Pid),
+		fmt.Sprintf("Elapsed: %s", c.exitedAt.Sub(c.last.StartedAt).Round(time.Second)),
+		fmt.Sprintf("CPU Time: %s", c.last.CPUTime),
+	}
+	if c.last.PeakRSS > 0 {
+		infoStrings = append(infoStrings, fmt.Sprintf("Peak Memory Usage: %s", formatter.PrettyPrintBytes(c.last.PeakRSS)))
+	}
+	return strings.Join(infoStrings, "\n")
+}
+
+// ProcessCommand returns p's command line, or its name if it has none.
+func ProcessCommand(p *monitor.Process) string {
+	if p.Cmdline != "" {
+		return p.Cmdline
+	}
+	return p.Name
+}
@@ -0,0 +1,38 @@
+package waiter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// dialTimeout keeps an unresponsive host from stalling a check for too long.
+const dialTimeout = 5 * time.Second
+
+type tcpCondition struct {
+	addr string
+}
+
+// TCP is met once addr (host:port) accepts connections.
+func TCP(addr string) Condition {
+	return &tcpCondition{addr: addr}
+}
+
+func (c *tcpCondition) Check(ctx context.Context) (bool, error) {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return false, err
+	}
+	conn.Close()
+	return true, nil
+}
+
+func (c *tcpCondition) Describe() string {
+	return fmt.Sprintf("%s to accept connections", c.addr)
+}
+
+func (c *tcpCondition) ReadyMessage() string {
+	return fmt.Sprintf("%s is accepting connections.", c.addr)
+}
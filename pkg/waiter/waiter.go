@@ -0,0 +1,66 @@
+package waiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidInterval = errors.New("invalid interval (expected a duration greater than 0, e.g. 1s)")
+
+// Condition is something n-cli wait can wait for.
+type Condition interface {
+	// Check reports whether the condition is met. An error means "not yet";
+	// the last one is reported if the wait times out.
+	Check(ctx context.Context) (bool, error)
+	// Describe says what is being waited for, e.g. "localhost:5432 to accept connections".
+	Describe() string
+	// ReadyMessage is the first line of the notification once the condition is met.
+	ReadyMessage() string
+}
+
+// NotMetError is returned by Wait when ctx ends before the condition is met.
+type NotMetError struct {
+	// Err is ctx's error, e.g. context.DeadlineExceeded.
+	Err error
+	// LastCheck is why the last check failed, if known.
+	LastCheck error
+}
+
+func (e *NotMetError) Error() string {
+	if e.LastCheck == nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s (last check: %s)", e.Err.Error(), e.LastCheck.Error())
+}
+
+func (e *NotMetError) Unwrap() error {
+	return e.Err
+}
+
+// Wait checks cond right away and then every interval until it is met or ctx
+// is done.
+func Wait(ctx context.Context, cond Condition, interval time.Duration) error {
+	if interval <= 0 {
+		return ErrInvalidInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var lastErr error
+	for {
+		ok, err := cond.Check(ctx)
+		if ok {
+			return nil
+		}
+		// a check cut short by ctx says nothing about the condition
+		if err != nil && ctx.Err() == nil {
+			lastErr = err
+		}
+		select {
+		case <-ctx.Done():
+			return &NotMetError{Err: ctx.Err(), LastCheck: lastErr}
+		case <-ticker.C:
+		}
+	}
+}
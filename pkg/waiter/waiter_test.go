@@ -0,0 +1,148 @@
+package waiter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingCondition struct {
+	checks  int
+	readyAt int
+}
+
+func (c *countingCondition) Check(ctx context.Context) (bool, error) {
+	c.checks++
+	if c.readyAt > 0 && c.checks >= c.readyAt {
+		return true, nil
+	}
+	return false, errors.New("not yet")
+}
+
+func (c *countingCondition) Describe() string     { return "the counter" }
+func (c *countingCondition) ReadyMessage() string { return "the counter is done." }
+
+func TestWait(t *testing.T) {
+	t.Run("returns once the condition is met", func(t *testing.T) {
+		cond := &countingCondition{readyAt: 3}
+		require.NoError(t, Wait(context.Background(), cond, time.Millisecond))
+		assert.Equal(t, 3, cond.checks)
+	})
+
+	t.Run("reports the last failed check when ctx ends", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := Wait(ctx, &countingCondition{}, time.Millisecond)
+		var notMet *NotMetError
+		require.ErrorAs(t, err, &notMet)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.EqualError(t, notMet.LastCheck, "not yet")
+	})
+
+	t.Run("rejects a non-positive interval", func(t *testing.T) {
+		for _, interval := range []time.Duration{0, -time.Second} {
+			cond := &countingCondition{readyAt: 1}
+			assert.ErrorIs(t, Wait(context.Background(), cond, interval), ErrInvalidInterval, interval)
+			assert.Equal(t, 0, cond.checks, interval)
+		}
+	})
+}
+
+func TestTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+
+	cond := TCP(addr)
+	ok, err := cond.Check(context.Background())
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, addr+" is accepting connections.", cond.ReadyMessage())
+
+	l.Close()
+	ok, err = cond.Check(context.Background())
+	assert.False(t, ok)
+	assert.Error(t, err)
+}
+
+func TestHTTP(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	body := `{"status": "starting"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	cond := HTTP(server.URL, HTTPOptions{BodyRegex: regexp.MustCompile(`"status": "ok"`)})
+	ok, err := cond.Check(context.Background())
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+	assert.ErrorContains(t, err, "503")
+
+	status = http.StatusOK
+	ok, err = cond.Check(context.Background())
+	assert.False(t, ok)
+	assert.ErrorContains(t, err, "body does not match")
+
+	body = `{"status": "ok"}`
+	ok, err = cond.Check(context.Background())
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+" is up (status 200).", cond.ReadyMessage())
+
+	t.Run("custom status codes", func(t *testing.T) {
+		status = http.StatusUnauthorized
+		ok, _ := HTTP(server.URL, HTTPOptions{StatusCodes: []int{401}}).Check(context.Background())
+		assert.True(t, ok)
+	})
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ready")
+
+	t.Run("waits for the file to be created", func(t *testing.T) {
+		cond := File(path)
+		ok, err := cond.Check(context.Background())
+		assert.False(t, ok)
+		assert.ErrorIs(t, err, ErrFileMissing)
+
+		require.NoError(t, os.WriteFile(path, []byte("1"), 0o644))
+		ok, _ = cond.Check(context.Background())
+		assert.True(t, ok)
+		assert.Equal(t, path+" has been created.", cond.ReadyMessage())
+	})
+
+	t.Run("waits for an existing file to change", func(t *testing.T) {
+		cond := File(path)
+		ok, err := cond.Check(context.Background())
+		assert.False(t, ok)
+		assert.ErrorIs(t, err, ErrFileUnchanged)
+
+		require.NoError(t, os.WriteFile(path, []byte("12"), 0o644))
+		ok, _ = cond.Check(context.Background())
+		assert.True(t, ok)
+		assert.Equal(t, path+" has changed.", cond.ReadyMessage())
+	})
+
+	t.Run("waits for the file to be removed", func(t *testing.T) {
+		cond := FileAbsent(path)
+		ok, err := cond.Check(context.Background())
+		assert.False(t, ok)
+		assert.ErrorIs(t, err, ErrFileExists)
+
+		require.NoError(t, os.Remove(path))
+		ok, _ = cond.Check(context.Background())
+		assert.True(t, ok)
+	})
+}